@@ -0,0 +1,103 @@
+package doublebuf
+
+import "slices"
+
+// shrinkAfter is the number of consecutive calls to Back that must find more
+// free buffers than they need before an adaptive DoubleBuffer shrinks.
+const shrinkAfter = 64
+
+// adaptive is the state of WithAdaptiveDepth.
+type adaptive[T any] struct {
+	min, max int
+	alloc    func() T
+	grown    map[*slot[T]]struct{} // buffers allocated by grow
+	spare    int                   // consecutive Backs that found a spare free buffer
+	ids      int                   // id of the last buffer allocated
+}
+
+// WithAdaptiveDepth lets the number of backing buffers vary between min and
+// max with the load. When a producer would block in Back for lack of a free
+// buffer, the DoubleBuffer grows by one buffer, allocated with alloc, and
+// hands it to the producer instead, as long as it has fewer than max
+// buffers; the surplus frames are then coalesced as with WithBuffers, so
+// bursts no longer stall the producer. When Back has found more free buffers
+// than it needed for many consecutive calls, one buffer allocated by
+// growing is dropped again, down to min buffers; buffers passed to the
+// constructor or WithBuffers are never dropped.
+// Growing allocates on the producer's goroutine, inside Back; nothing is
+// allocated while the DoubleBuffer stays within its current depth. If the
+// constructor provides fewer than min buffers, the missing ones are
+// allocated up front. A nil alloc allocates zero values of T.
+// WithAdaptiveDepth panics if min is less than 2 or max is less than min.
+func WithAdaptiveDepth[T any](min, max int, alloc func() T) Option[T] {
+	if min < 2 || max < min {
+		panic("doublebuf: WithAdaptiveDepth requires 2 <= min <= max")
+	}
+	return func(db *DoubleBuffer[T]) {
+		db.adapt = &adaptive[T]{
+			min: min, max: max,
+			alloc: alloc,
+			grown: make(map[*slot[T]]struct{}),
+		}
+	}
+}
+
+// init allocates buffers up to the minimum depth.
+func (a *adaptive[T]) init(db *DoubleBuffer[T]) {
+	a.ids = len(db.slots) - 1
+	for len(db.slots) < a.min {
+		db.slots = append(db.slots, db.newSlot())
+	}
+	if len(db.slots) > a.max {
+		panic("doublebuf: more buffers than the maximum of WithAdaptiveDepth")
+	}
+}
+
+// newSlot allocates a backing buffer for an adaptive DoubleBuffer.
+func (db *DoubleBuffer[T]) newSlot() *slot[T] {
+	db.adapt.ids++
+	s := &slot[T]{p: new(T), id: db.adapt.ids}
+	if db.adapt.alloc != nil {
+		db.invoke("alloc", func() { *s.p = db.adapt.alloc() })
+	}
+	db.adapt.grown[s] = struct{}{}
+	return s
+}
+
+// grow allocates a new buffer for a producer that would otherwise block.
+func (db *DoubleBuffer[T]) grow() (*slot[T], bool) {
+	db.slotsMu.Lock()
+	defer db.slotsMu.Unlock()
+	db.adapt.spare = 0
+	if len(db.slots) >= db.adapt.max {
+		return nil, false
+	}
+	s := db.newSlot()
+	db.slots = append(db.slots, s)
+	return s, true
+}
+
+// maybeShrink drops a grown buffer once free buffers have been in surplus
+// for shrinkAfter consecutive calls.
+func (db *DoubleBuffer[T]) maybeShrink() {
+	db.slotsMu.Lock()
+	defer db.slotsMu.Unlock()
+	if len(db.prev) == 0 || len(db.slots) <= db.adapt.min {
+		db.adapt.spare = 0
+		return
+	}
+	if db.adapt.spare++; db.adapt.spare < shrinkAfter {
+		return
+	}
+	db.adapt.spare = 0
+	select {
+	case s := <-db.prev:
+		if _, ok := db.adapt.grown[s]; !ok {
+			db.prev <- s // not ours to drop, try again later
+			return
+		}
+		delete(db.adapt.grown, s)
+		db.slots = slices.DeleteFunc(db.slots, func(q *slot[T]) bool { return q == s })
+	default:
+	}
+}
@@ -0,0 +1,39 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAdaptiveDepth(t *testing.T) {
+	db := New(0, 0, WithAdaptiveDepth(2, 4, func() int { return 0 }))
+	// The consumer is stalled, so the producer grows a third buffer, after
+	// which frames are coalesced and it never has to wait.
+	for i := 1; i <= 3; i++ {
+		back, ok := db.TryBack()
+		if !ok {
+			t.Fatalf("TryBack %d failed below the maximum depth", i)
+		}
+		*back = i
+		db.Ready()
+	}
+	if got := len(db.slots); got != 3 {
+		t.Fatalf("depth = %d, want 3", got)
+	}
+	if v, _ := db.Next(); v != 3 {
+		t.Fatalf("Next = %d, want the latest frame 3", v)
+	}
+	// The consumer keeps up again, so the surplus buffers are dropped.
+	for i := 0; i < 4*shrinkAfter; i++ {
+		back, err := db.Back(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		*back = i
+		db.Ready()
+		db.Next()
+	}
+	if got := len(db.slots); got != 2 {
+		t.Fatalf("depth after the burst = %d, want 2", got)
+	}
+}
@@ -0,0 +1,43 @@
+package doublebuf
+
+// cacheLine is an upper bound of the cache line size of common CPUs.
+const cacheLine = 128
+
+// aligned holds a buffer and its slot on cache lines of their own.
+type aligned[T any] struct {
+	_    [cacheLine]byte
+	slot slot[T]
+	v    T
+	_    [cacheLine]byte
+}
+
+// WithCacheAligned gives every backing buffer cache lines of its own. By
+// default New stores the buffers next to each other, so the back buffer the
+// producer writes and the front buffer consumers read may share a cache
+// line, and every write by the producer evicts that line from the caches of
+// the consumers (false sharing). With WithCacheAligned, New and WithBuffers
+// copy the initial values into separate padded heap allocations instead,
+// at the cost of two cache lines of padding per buffer. This matters for
+// small T read and written concurrently on different cores; a large T
+// spans many cache lines anyway.
+// The option has no effect on the buffers passed to NewPtr, whose storage
+// belongs to the caller, nor on buffers allocated by WithAdaptiveDepth.
+func WithCacheAligned[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.cacheAligned = true }
+}
+
+// align moves the buffers stored in db to padded allocations. If inline is
+// false, the first two buffers belong to the caller of NewPtr and stay.
+func (db *DoubleBuffer[T]) align(inline bool) {
+	for i, s := range db.slots {
+		if i < 2 && !inline {
+			continue
+		}
+		c := new(aligned[T])
+		c.v = *s.p
+		c.slot.p, c.slot.id = &c.v, s.id
+		// Keep the per-slot state set up by init, e.g. by WithUserData.
+		c.slot.readyAt, c.slot.data, c.slot.dirty = s.readyAt, s.data, s.dirty
+		db.slots[i] = &c.slot
+	}
+}
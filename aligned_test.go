@@ -0,0 +1,53 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+)
+
+func TestWithCacheAligned(t *testing.T) {
+	db := New(1, 2, WithCacheAligned[int]())
+	back, _ := db.Back(context.Background())
+	if back == &db.a || *back != 1 {
+		t.Fatal("back buffer not moved out of the DoubleBuffer")
+	}
+	front := db.front.Load().p
+	d := uintptr(unsafe.Pointer(front)) - uintptr(unsafe.Pointer(back))
+	if int(d) > -cacheLine && int(d) < cacheLine {
+		t.Fatalf("buffers %d bytes apart, want at least %d", d, cacheLine)
+	}
+	if v := db.Front(); v != 2 {
+		t.Fatalf("Front = %d, want 2", v)
+	}
+}
+
+// BenchmarkCacheAligned measures concurrent Front reads while a producer
+// keeps writing its back buffer, with adjacent and with padded buffers.
+func BenchmarkCacheAligned(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option[int64]
+	}{
+		{"Adjacent", nil},
+		{"Aligned", []Option[int64]{WithCacheAligned[int64]()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			db := New(0, 0, bc.opts...)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				back, _ := db.Back(ctx)
+				for i := int64(0); ctx.Err() == nil; i++ {
+					*back = i
+				}
+			}()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = db.Front()
+				}
+			})
+		})
+	}
+}
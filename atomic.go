@@ -0,0 +1,95 @@
+package doublebuf
+
+import (
+	"context"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Numeric is the set of scalar types an AtomicBuffer can carry: integers and
+// floating-point numbers of at most 64 bits.
+type Numeric interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// AtomicBuffer is a latest-value handoff for a scalar, with the producer and
+// consumer API of a DoubleBuffer as a facade over a few atomic words, so
+// code can use one API for scalar and struct payloads while scalars get a
+// truly lock-free path. Back returns a scratch value owned by the producer,
+// Ready publishes it and Next swaps the latest published value in as the
+// front.
+//
+// Three words are needed because the facade keeps the two roles apart:
+// latest holds the published value, seq counts publications, so Next can
+// report a change even if the same value is published twice, and front
+// holds the value of the last Next, so Front is stable between swaps.
+// Ready stores latest before bumping seq, and Next loads seq before
+// latest, so the value Next returns is at least as new as the publication
+// it reports.
+//
+// The facade differs from a DoubleBuffer in ordering and drops: there are no
+// buffers to hand back, so Back never blocks and every Ready overwrites the
+// published value, as with LastWins, without counting a drop. A Next that
+// runs concurrently with Ready may return the newer value yet report the
+// change again on the following call. There are no options, statistics or
+// close.
+type AtomicBuffer[T Numeric] struct {
+	latest  atomic.Uint64 // bits of the last published value
+	seq     atomic.Uint64 // number of values published
+	front   atomic.Uint64 // bits of the front value
+	scratch T             // producer only
+	swapped uint64        // consumer only, seq as of the last Next
+}
+
+// NewAtomic creates an AtomicBuffer holding zero values.
+func NewAtomic[T Numeric]() *AtomicBuffer[T] {
+	return new(AtomicBuffer[T])
+}
+
+// Back returns the scratch value for the producer to fill; it never blocks
+// and always returns a nil error. The scratch value keeps whatever the
+// producer last wrote to it.
+// Back, TryBack and Ready must only be called by one producer goroutine.
+func (a *AtomicBuffer[T]) Back(context.Context) (*T, error) { return &a.scratch, nil }
+
+// TryBack is like Back and always succeeds.
+func (a *AtomicBuffer[T]) TryBack() (*T, bool) { return &a.scratch, true }
+
+// Ready publishes the scratch value, replacing any value not yet swapped in.
+func (a *AtomicBuffer[T]) Ready() {
+	a.latest.Store(toBits(a.scratch))
+	a.seq.Add(1)
+}
+
+// Next makes the latest published value the front and returns it, with
+// changed reporting whether anything was published since the previous Next.
+// Next must only be called by one consumer goroutine.
+func (a *AtomicBuffer[T]) Next() (t T, changed bool) {
+	// Loading seq first means latest is at least as new as seq says.
+	seq := a.seq.Load()
+	if seq == a.swapped {
+		return fromBits[T](a.front.Load()), false
+	}
+	bits := a.latest.Load()
+	a.front.Store(bits)
+	a.swapped = seq
+	return fromBits[T](bits), true
+}
+
+// Front returns the front value, i.e. the value returned by the last Next.
+// Front is safe to call concurrently with all other methods.
+func (a *AtomicBuffer[T]) Front() T { return fromBits[T](a.front.Load()) }
+
+// toBits stores v in the low-addressed bytes of a word; fromBits reads it
+// back the same way, so the encoding does not depend on endianness.
+func toBits[T Numeric](v T) uint64 {
+	var u uint64
+	*(*T)(unsafe.Pointer(&u)) = v
+	return u
+}
+
+func fromBits[T Numeric](u uint64) T {
+	return *(*T)(unsafe.Pointer(&u))
+}
@@ -0,0 +1,58 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAtomicBuffer(t *testing.T) {
+	a := NewAtomic[float64]()
+	if v, changed := a.Next(); changed || v != 0 {
+		t.Fatalf("Next before Ready = %g, %t; want 0, false", v, changed)
+	}
+	back, _ := a.Back(context.Background())
+	*back = 1.5
+	a.Ready()
+	*back = -2.25
+	a.Ready() // overwrites 1.5
+	if v, changed := a.Next(); !changed || v != -2.25 {
+		t.Fatalf("Next = %g, %t; want -2.25, true", v, changed)
+	}
+	if v, changed := a.Next(); changed || v != -2.25 {
+		t.Fatalf("second Next = %g, %t; want -2.25, false", v, changed)
+	}
+	if v := a.Front(); v != -2.25 {
+		t.Fatalf("Front = %g, want -2.25", v)
+	}
+
+	i := NewAtomic[int8]()
+	p, _ := i.TryBack()
+	*p = -1
+	i.Ready()
+	if v, _ := i.Next(); v != -1 {
+		t.Fatalf("Next of int8 = %d, want -1", v)
+	}
+}
+
+func TestAtomicBufferConcurrent(t *testing.T) {
+	const frames = 10000
+	a := NewAtomic[uint32]()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		back, _ := a.Back(context.Background())
+		for i := uint32(1); i <= frames; i++ {
+			*back = i
+			a.Ready()
+		}
+	}()
+	var last uint32
+	for last != frames {
+		v, _ := a.Next()
+		if v < last {
+			t.Fatalf("Next went back from %d to %d", last, v)
+		}
+		last = v
+	}
+	<-done
+}
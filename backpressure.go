@@ -0,0 +1,86 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithBackpressure makes publishing lossless: instead of replacing a readied
+// frame that the consumer has not swapped in yet, Ready blocks until it has.
+// This only makes a difference together with WithBuffers, since with two
+// buffers the producer cannot ready a second frame before the consumer swaps.
+// Use ReadyContext to bound the wait.
+func WithBackpressure[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.backpressure = true }
+}
+
+// ReadyContext is like Ready, but reports why the back buffer could not be
+// readied. In backpressure mode it waits for the consumer to swap in the
+// pending frame, and returns ctx.Err() if ctx is done first; the producer
+// then keeps the back buffer and may call ReadyContext again.
+// ReadyContext returns ErrClosed once the DoubleBuffer has been closed,
+// including while waiting, and ErrTooLong if WithMaxLen rejected the frame.
+// The concurrency rules of Ready apply.
+func (db *DoubleBuffer[T]) ReadyContext(ctx context.Context) error {
+	db.checkSingleProducer("ReadyContext")
+	return db.ready(ctx, time.Time{}, false)
+}
+
+// ErrFull is returned by TryReady when a readied frame is still pending and
+// the DoubleBuffer would otherwise block or drop the new frame.
+var ErrFull = errors.New("doublebuf: a readied frame is still pending")
+
+// TryReady is like Ready, but never blocks and never drops the frame it
+// readies: if a readied frame is still pending and the DoubleBuffer would
+// hold the new one back, i.e. in backpressure mode, where ReadyContext
+// waits for the consumer, and under the FirstWins policy of
+// WithReadyPolicy, where Ready drops the new frame, TryReady returns
+// ErrFull instead. The producer then keeps the back buffer and decides what
+// to do, e.g. retry later, fold more data into the frame, or log the
+// overflow. Otherwise, under the default LastWins policy or with
+// WithMergeOnOverwrite, the pending frame gives way as it does for Ready,
+// so TryReady never returns ErrFull.
+// TryReady returns ErrClosed once the DoubleBuffer has been closed, and
+// ErrTooLong if WithMaxLen rejected the frame.
+// The concurrency rules of Ready apply.
+func (db *DoubleBuffer[T]) TryReady() error {
+	db.checkSingleProducer("TryReady")
+	return db.ready(context.Background(), time.Time{}, true)
+}
+
+// publishTry makes s the pending buffer if no other buffer is pending, and
+// returns ErrFull otherwise.
+func (db *DoubleBuffer[T]) publishTry(s *slot[T]) error {
+	if !db.next.CompareAndSwap(nil, s) {
+		return ErrFull
+	}
+	db.readied.notify()
+	return nil
+}
+
+// publishWait makes s the pending buffer once no other buffer is pending.
+func (db *DoubleBuffer[T]) publishWait(ctx context.Context, s *slot[T]) error {
+	if db.next.CompareAndSwap(nil, s) {
+		db.readied.notify()
+		return nil
+	}
+	for {
+		// Grab the wait channel before retrying, so a swap in between is not missed.
+		swapped := db.swapped.wait()
+		if db.closed.Load() {
+			return ErrClosed
+		}
+		if db.next.CompareAndSwap(nil, s) {
+			db.readied.notify()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-db.done:
+			return ErrClosed
+		case <-swapped:
+		}
+	}
+}
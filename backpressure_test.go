@@ -0,0 +1,95 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackpressure(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithBackpressure[int]())
+	for i := 1; i <= 2; i++ {
+		back, ok := db.TryBack()
+		if !ok {
+			t.Fatalf("TryBack %d failed", i)
+		}
+		*back = i
+		if i == 1 {
+			db.Ready()
+			continue
+		}
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			if v, _ := db.Next(); v != 1 {
+				t.Errorf("Next = %d, want 1", v)
+			}
+		}()
+		if err := db.ReadyContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if v, _ := db.Next(); v != 2 {
+		t.Fatalf("Next = %d, want 2", v)
+	}
+}
+
+func TestReadyContextCancel(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithBackpressure[int]())
+	db.TryBack()
+	db.Ready()
+	back, _ := db.TryBack()
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error)
+	go func() { errc <- db.ReadyContext(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("ReadyContext = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ReadyContext did not unblock on cancel")
+	}
+	if again, _ := db.TryBack(); again != back {
+		t.Fatal("producer lost its back buffer after a cancelled ReadyContext")
+	}
+}
+
+func TestTryReady(t *testing.T) {
+	for name, opt := range map[string]Option[int]{
+		"Backpressure": WithBackpressure[int](),
+		"FirstWins":    WithReadyPolicy[int](FirstWins),
+	} {
+		db := New(0, 0, WithBuffers(0), opt)
+		db.Publish(context.Background(), 1)
+		back, _ := db.Back(context.Background())
+		*back = 2
+		if err := db.TryReady(); !errors.Is(err, ErrFull) {
+			t.Fatalf("%s: TryReady with a frame pending = %v, want ErrFull", name, err)
+		}
+		if got, _ := db.TryBack(); got != back {
+			t.Fatalf("%s: producer lost its buffer after ErrFull", name)
+		}
+		if v, _ := db.Next(); v != 1 {
+			t.Fatalf("%s: Next = %d, want the pending frame 1", name, v)
+		}
+		if err := db.TryReady(); err != nil {
+			t.Fatalf("%s: TryReady once the frame was consumed = %v", name, err)
+		}
+		if v, _ := db.Next(); v != 2 {
+			t.Fatalf("%s: Next = %d, want 2", name, v)
+		}
+		if d := db.Dropped(); d != 0 {
+			t.Fatalf("%s: Dropped = %d, want 0", name, d)
+		}
+	}
+
+	db := New(0, 0, WithBuffers(0))
+	db.Publish(context.Background(), 1)
+	db.Back(context.Background())
+	if err := db.TryReady(); err != nil {
+		t.Fatalf("TryReady under LastWins = %v, want nil", err)
+	}
+}
@@ -0,0 +1,113 @@
+package doublebuf
+
+import (
+	"context"
+	"sync"
+)
+
+// barrier coordinates the consumers registered with RegisterConsumer.
+type barrier[T any] struct {
+	mu         sync.Mutex
+	registered int
+	arrived    int
+	round      chan struct{} // closed when the current round completes
+	value      T             // front handed out by the last completed round
+	changed    bool
+}
+
+// BarrierConsumer is a consumer taking part in a swap barrier, see
+// RegisterConsumer.
+type BarrierConsumer[T any] struct {
+	db           *DoubleBuffer[T]
+	unregistered bool
+}
+
+// RegisterConsumer registers a consumer with the swap barrier of db.
+// Registered consumers advance together: each calls NextBarrier, and the
+// call blocks until every registered consumer has arrived. The last one to
+// arrive performs the swap, and all of them then receive the same front, so
+// the whole group processes each frame before any of them moves on.
+// Consumers that are done must call Unregister, otherwise the others wait
+// for them forever.
+func (db *DoubleBuffer[T]) RegisterConsumer() *BarrierConsumer[T] {
+	b := &db.barrier
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.round == nil {
+		b.round = make(chan struct{})
+	}
+	b.registered++
+	return &BarrierConsumer[T]{db: db}
+}
+
+// NextBarrier waits for all registered consumers to arrive, then returns the
+// front they all share, with changed reporting whether the round swapped in
+// a new frame, as for Next.
+// If ctx is done first, the consumer's arrival is withdrawn and NextBarrier
+// returns ctx.Err().
+// A BarrierConsumer must not call NextBarrier concurrently with itself.
+func (c *BarrierConsumer[T]) NextBarrier(ctx context.Context) (t T, changed bool, err error) {
+	b := &c.db.barrier
+	b.mu.Lock()
+	if c.unregistered {
+		b.mu.Unlock()
+		panic("doublebuf: NextBarrier called after Unregister")
+	}
+	round := b.round
+	b.arrived++
+	if b.arrived == b.registered {
+		c.db.completeRound()
+		t, changed = b.value, b.changed
+		b.mu.Unlock()
+		return t, changed, nil
+	}
+	b.mu.Unlock()
+	select {
+	case <-round:
+	case <-ctx.Done():
+		b.mu.Lock()
+		if b.round != round { // the round completed anyway
+			b.mu.Unlock()
+			return b.roundValue()
+		}
+		b.arrived--
+		b.mu.Unlock()
+		return t, false, ctx.Err()
+	}
+	return b.roundValue()
+}
+
+// Unregister removes the consumer from the barrier. If the remaining
+// consumers have all arrived, their round completes.
+// Calling Unregister multiple times is idempotent.
+func (c *BarrierConsumer[T]) Unregister() {
+	b := &c.db.barrier
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c.unregistered {
+		return
+	}
+	c.unregistered = true
+	b.registered--
+	if b.registered > 0 && b.arrived == b.registered {
+		c.db.completeRound()
+	}
+}
+
+// completeRound swaps and releases the waiting consumers; b.mu must be held.
+func (db *DoubleBuffer[T]) completeRound() {
+	b := &db.barrier
+	b.value, b.changed = db.Next()
+	b.arrived = 0
+	close(b.round)
+	b.round = make(chan struct{})
+}
+
+// roundValue returns the result of the round the caller waited for.
+// Only the latest round can be observed, which is fine because a new round
+// cannot complete before every consumer of the previous one has arrived again.
+func (b *barrier[T]) roundValue() (t T, changed bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.value, b.changed, nil
+}
@@ -0,0 +1,57 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBarrier(t *testing.T) {
+	const consumers = 3
+	db := New(0, 0)
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.Ready()
+	cs := make([]*BarrierConsumer[int], consumers)
+	for i := range cs {
+		cs[i] = db.RegisterConsumer()
+	}
+	var wg sync.WaitGroup
+	results := make([]int, consumers)
+	for i, c := range cs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, _, err := c.NextBarrier(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = v
+		}()
+	}
+	wg.Wait()
+	for i, v := range results {
+		if v != 1 {
+			t.Fatalf("consumer %d got %d, want 1", i, v)
+		}
+	}
+	if got := db.SwapCount(); got != 1 {
+		t.Fatalf("SwapCount = %d, want 1", got)
+	}
+}
+
+func TestBarrierWaitsForAll(t *testing.T) {
+	db := New(0, 0)
+	a, b := db.RegisterConsumer(), db.RegisterConsumer()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := a.NextBarrier(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("NextBarrier alone = %v, want DeadlineExceeded", err)
+	}
+	b.Unregister()
+	if _, _, err := a.NextBarrier(context.Background()); err != nil {
+		t.Fatalf("NextBarrier after the other consumer unregistered = %v", err)
+	}
+}
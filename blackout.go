@@ -0,0 +1,32 @@
+package doublebuf
+
+import "time"
+
+// BlackoutUntil suppresses swaps until t, as measured with the clock of
+// WithClock, e.g. to keep the consumer's view stable through a known busy
+// phase. Until then Next returns the current front with changed set to
+// false even if a frame is pending; frames readied meanwhile are coalesced
+// as usual, so with WithBuffers all but the most recent one are dropped, and
+// with two buffers the producer blocks in Back once it has readied a frame.
+// The first Next after t promotes the most recently readied frame.
+// Frames and FrontNewerThan wait for the blackout to end before swapping.
+// A later call replaces the blackout, and a t in the past ends it.
+// BlackoutUntil is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) BlackoutUntil(t time.Time) {
+	db.blackout.Store(int64(t.Sub(db.epoch)))
+}
+
+// blackoutLeft returns how much longer swaps are suppressed, or 0 if they
+// are not.
+func (db *DoubleBuffer[T]) blackoutLeft() time.Duration {
+	until := db.blackout.Load()
+	if until == 0 {
+		return 0
+	}
+	left := time.Duration(until) - db.now().Sub(db.epoch)
+	if left <= 0 {
+		db.blackout.CompareAndSwap(until, 0)
+		return 0
+	}
+	return left
+}
@@ -0,0 +1,37 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlackoutUntil(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now))
+	db.BlackoutUntil(clock.now().Add(time.Second))
+	db.Publish(context.Background(), 1)
+	if v, changed := db.Next(); changed || v != 0 {
+		t.Fatalf("Next during blackout = %d, %t; want 0, false", v, changed)
+	}
+	clock.advance(time.Second)
+	if v, changed := db.Next(); !changed || v != 1 {
+		t.Fatalf("Next after blackout = %d, %t; want 1, true", v, changed)
+	}
+}
+
+func TestBlackoutFrames(t *testing.T) {
+	db := New(0, 0)
+	db.BlackoutUntil(time.Now().Add(20 * time.Millisecond))
+	db.Publish(context.Background(), 1)
+	start := time.Now()
+	for v := range db.Frames(context.Background()) {
+		if v != 1 {
+			t.Fatalf("Frames yielded %d, want 1", v)
+		}
+		break
+	}
+	if d := time.Since(start); d < 10*time.Millisecond {
+		t.Fatalf("Frames yielded after %v, before the blackout ended", d)
+	}
+}
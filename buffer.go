@@ -0,0 +1,39 @@
+package doublebuf
+
+import (
+	"context"
+	"time"
+)
+
+// Buffer is the part of the DoubleBuffer API that does not depend on T:
+// lifecycle and statistics. *DoubleBuffer[T] implements it for every T, so
+// DoubleBuffers of different types can be managed together, e.g. in a
+// []Buffer registry.
+type Buffer interface {
+	Close() error
+	CloseWithError(err error) error
+	Err() error
+	CloseDrain(ctx context.Context) error
+	WaitEmpty(ctx context.Context) error
+
+	SwapCount() uint64
+	IdleNext() uint64
+	Dropped() uint64
+	Filtered() uint64
+	OutOfOrder() uint64
+	Rejected() uint64
+	Throttled() uint64
+	RetiredLost() uint64
+	BackCount() uint64
+	ReadyCount() uint64
+	InFlight() int
+	Cap() int
+	Depth() int
+	BackWaitTotal() time.Duration
+	BackCancelled() uint64
+	Generation() uint64
+	LastSwap() time.Time
+	ResetStats()
+}
+
+var _ Buffer = (*DoubleBuffer[struct{}])(nil)
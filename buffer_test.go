@@ -0,0 +1,25 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuffer(t *testing.T) {
+	ints, strs := New(0, 0), New("", "")
+	ints.Back(context.Background())
+	ints.Ready()
+	ints.Next()
+	registry := []Buffer{ints, strs}
+	var swaps uint64
+	for _, b := range registry {
+		swaps += b.SwapCount()
+		b.Close()
+	}
+	if swaps != 1 {
+		t.Fatalf("total SwapCount = %d, want 1", swaps)
+	}
+	if _, err := strs.Back(context.Background()); err != ErrClosed {
+		t.Fatalf("Back after closing through Buffer = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,49 @@
+package doublebuf
+
+import "fmt"
+
+// CallbackPanic describes a panic in a user-supplied callback, such as the
+// hook passed to WithOnRecycle or the Release method of a BufferSource.
+type CallbackPanic struct {
+	Callback string // name of the callback, e.g. "OnRecycle"
+	Value    any    // value passed to panic
+}
+
+func (p *CallbackPanic) Error() string {
+	return fmt.Sprintf("doublebuf: %s callback panicked: %v", p.Callback, p.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (p *CallbackPanic) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+// WithPanicHandler recovers panics in user callbacks and reports them to
+// handler as a *CallbackPanic, after which the operation that invoked the
+// callback carries on as if it had returned normally.
+//
+// Callbacks are expected not to panic. Without a panic handler, a panic in
+// a callback propagates as a *CallbackPanic to the caller of the method that
+// invoked it, but only after the DoubleBuffer's own state is consistent
+// again, so a buggy callback never leaves buffers lost or half-swapped.
+// WithPanicHandler(nil) keeps that default.
+func WithPanicHandler[T any](handler func(error)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.onPanic = handler }
+}
+
+// invoke calls the user callback named name.
+// Callers must only invoke callbacks at points where the DoubleBuffer's
+// state is consistent, since a panic may unwind past them.
+func (db *DoubleBuffer[T]) invoke(name string, f func()) {
+	defer func() {
+		if v := recover(); v != nil {
+			err := &CallbackPanic{Callback: name, Value: v}
+			if db.onPanic == nil {
+				panic(err)
+			}
+			db.onPanic(err)
+		}
+	}()
+	f()
+}
@@ -0,0 +1,43 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCallbackPanic(t *testing.T) {
+	db := New(0, 0, WithOnRecycle(func(*int) { panic("boom") }))
+	db.Publish(context.Background(), 1)
+	db.Next()
+	func() {
+		defer func() {
+			var p *CallbackPanic
+			if err, _ := recover().(error); !errors.As(err, &p) || p.Callback != "OnRecycle" {
+				t.Fatalf("recovered %v, want an OnRecycle *CallbackPanic", err)
+			}
+		}()
+		db.Back(context.Background())
+	}()
+	// The buffer survived the panic.
+	if _, ok := db.TryBack(); !ok {
+		t.Fatal("back buffer lost after a panicking callback")
+	}
+}
+
+func TestWithPanicHandler(t *testing.T) {
+	var handled error
+	db := New(0, 0,
+		WithOnRecycle(func(*int) { panic("boom") }),
+		WithPanicHandler[int](func(err error) { handled = err }),
+	)
+	db.Publish(context.Background(), 1)
+	db.Next()
+	if _, err := db.Back(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var p *CallbackPanic
+	if !errors.As(handled, &p) || p.Value != "boom" {
+		t.Fatalf("handled %v, want a *CallbackPanic with value boom", handled)
+	}
+}
@@ -0,0 +1,21 @@
+package doublebuf
+
+// Checkpoint returns copies of the front value and, if a readied frame is
+// pending, of that frame, e.g. to persist the complete state of a pipeline
+// for recovery. backValid reports whether back holds such a frame. A back
+// buffer that a producer holds is still being filled and is never read, so
+// backValid is false while the producer is mid-frame; restoring front and,
+// if valid, back therefore loses at most the frame in progress.
+// Checkpoint holds the lock that serializes swaps, so both values belong to
+// the same moment with respect to Next. It must not run concurrently with
+// Ready or ReadyBack: a Ready that replaces the pending frame recycles its
+// buffer, and a producer may start overwriting it while it is copied.
+func (db *DoubleBuffer[T]) Checkpoint() (front T, back T, backValid bool) {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	front = db.copyOut(*db.loadFront().p)
+	if next := db.next.Load(); next != nil {
+		back, backValid = db.copyOut(*next.p), true
+	}
+	return front, back, backValid
+}
@@ -0,0 +1,23 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckpoint(t *testing.T) {
+	db := New(0, 1)
+	back, _ := db.Back(context.Background())
+	*back = 2
+	if f, b, ok := db.Checkpoint(); f != 1 || ok {
+		t.Fatalf("Checkpoint mid-fill = %d, %d, %t; want 1, _, false", f, b, ok)
+	}
+	db.Ready()
+	if f, b, ok := db.Checkpoint(); f != 1 || b != 2 || !ok {
+		t.Fatalf("Checkpoint with a pending frame = %d, %d, %t; want 1, 2, true", f, b, ok)
+	}
+	db.Next()
+	if f, _, ok := db.Checkpoint(); f != 2 || ok {
+		t.Fatalf("Checkpoint after Next = %d, _, %t; want 2, _, false", f, ok)
+	}
+}
@@ -0,0 +1,155 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned by operations on a closed DoubleBuffer.
+var ErrClosed = errors.New("doublebuf: closed")
+
+// Close closes the DoubleBuffer abruptly.
+// After Close, Back returns ErrClosed, TryBack fails and Ready is a no-op.
+// Producers blocked in Back are woken and return ErrClosed.
+// A buffer that was readied but not yet consumed by Next is discarded;
+// use CloseDrain to deliver it instead.
+// Front keeps returning the last front buffer.
+// A Ready that runs concurrently with Close may still publish its buffer.
+// Close is safe to call concurrently with all other methods, calling it
+// multiple times is idempotent, and it always returns nil.
+// Close releases the backing buffers of a DoubleBuffer created by
+// NewFromSource.
+func (db *DoubleBuffer[T]) Close() error {
+	db.closeKeepBuffers()
+	db.release()
+	return nil
+}
+
+// closeKeepBuffers closes db like Close, but leaves the buffers of a sourced
+// DoubleBuffer with it.
+func (db *DoubleBuffer[T]) closeKeepBuffers() {
+	db.shutdown(nil)
+	db.next.Store(nil)
+	db.abortOnce.Do(func() { close(db.aborted) })
+	db.swapped.notify() // wake CloseDrain, the pending buffer is gone
+}
+
+// CloseWithError closes the DoubleBuffer like Close, and records err as the
+// reason, typically the failure that stopped the producer. Once no readied
+// frame is left, NextErr and FrontNewerThan return an error wrapping err
+// instead of ErrClosed, so errors.Is(returned, err) holds and consumers can
+// react to the actual failure; the recorded error takes precedence over
+// ErrClosed, which it does not match. Frames ends its iteration as for any
+// close, and Err reports err afterwards. Producers still see ErrClosed.
+// Only the first close counts: CloseWithError on a DoubleBuffer that is
+// already closed records nothing, and CloseWithError(nil) is equivalent to
+// Close.
+func (db *DoubleBuffer[T]) CloseWithError(err error) error {
+	db.shutdown(err)
+	return db.Close()
+}
+
+// Err returns the error recorded by CloseWithError, or nil if the
+// DoubleBuffer was closed without one or is still open.
+func (db *DoubleBuffer[T]) Err() error {
+	if !db.closed.Load() {
+		return nil
+	}
+	return db.closeErr
+}
+
+// CloseDrain closes the DoubleBuffer gracefully.
+// Like Close, it stops producers, but a buffer that was already readied
+// remains available to Next. CloseDrain blocks until that buffer has been
+// consumed by Next, or until ctx is done, in which case it returns ctx.Err()
+// and the buffer remains pending.
+// CloseDrain returns immediately if no buffer is pending.
+// The readied frame is delivered exactly once: exactly one call to Next,
+// NextErr or an iteration of Frames swaps it in, even with several
+// consumers racing CloseDrain, and NextErr and Frames only report the end of
+// the stream after that swap.
+// Once drained, the backing buffers of a DoubleBuffer created by
+// NewFromSource are released.
+func (db *DoubleBuffer[T]) CloseDrain(ctx context.Context) error {
+	db.shutdown(nil)
+	if err := db.WaitEmpty(ctx); err != nil {
+		return err
+	}
+	db.release()
+	return nil
+}
+
+// WaitEmpty blocks until no readied frame is pending, i.e. until the
+// consumer has swapped in everything the producer readied, and returns
+// ctx.Err() if ctx is done first. A producer can use it between phases of
+// work, so it does not start a new phase before the consumer has caught up.
+// WaitEmpty returns immediately if nothing is pending.
+// WaitEmpty is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) WaitEmpty(ctx context.Context) error {
+	for {
+		// Grab the wait channel before checking, so a swap in between is not missed.
+		swapped := db.swapped.wait()
+		if !db.pending() {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-swapped:
+		}
+	}
+}
+
+// BindContext ties the lifetime of the DoubleBuffer to ctx: once ctx is
+// done, the DoubleBuffer is closed as by Close. This keeps request-scoped
+// buffers from leaking when their owner forgets to close them. Operations
+// after the automatic close behave as documented for a closed DoubleBuffer.
+// The close happens on a goroutine of its own, while the producer and the
+// consumer may still be using the buffers, so it does not release the
+// buffers of a DoubleBuffer created by NewFromSource: call Close or
+// CloseDrain once both have stopped to release them, see BufferSource.
+// Calling the returned stop function unbinds ctx; it reports whether it
+// stopped the close from happening, like the stop function of
+// context.AfterFunc.
+func (db *DoubleBuffer[T]) BindContext(ctx context.Context) (stop func() bool) {
+	return context.AfterFunc(ctx, db.closeKeepBuffers)
+}
+
+// NextErr is like Next, but returns ErrClosed once the DoubleBuffer has
+// been closed and no readied frame is left to swap in, so that a consumer
+// loop can tell the end of the stream from an idle producer. t is then the
+// last front value. After CloseWithError the error wraps the recorded error
+// instead. With WithNextSendPolicy(SendError), NextErr also returns
+// ErrRetireFull from a swap that could not hand back the retired buffer.
+func (db *DoubleBuffer[T]) NextErr() (t T, changed bool, err error) {
+	r := db.advance()
+	if !r.Changed && db.closed.Load() && !db.pending() {
+		return r.Value, false, db.errClosed()
+	}
+	return r.Value, r.Changed, r.Err
+}
+
+// shutdown marks the DoubleBuffer as closed, recording err as the reason, and
+// wakes blocked producers. Only the first call has an effect.
+func (db *DoubleBuffer[T]) shutdown(err error) {
+	db.closeOnce.Do(func() {
+		db.closeErr = err
+		db.closed.Store(true)
+		close(db.done)
+	})
+}
+
+// errClosed returns the error reported to consumers at the end of the
+// stream: ErrClosed, or the error recorded by CloseWithError.
+func (db *DoubleBuffer[T]) errClosed() error {
+	if db.closeErr != nil {
+		return fmt.Errorf("doublebuf: closed with error: %w", db.closeErr)
+	}
+	return ErrClosed
+}
+
+// pending reports whether a readied buffer is waiting for Next.
+func (db *DoubleBuffer[T]) pending() bool {
+	return db.next.Load() != nil
+}
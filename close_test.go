@@ -0,0 +1,264 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	db := New(0, 0)
+	back, err := db.Back(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	*back = 1
+	db.Ready()
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Back(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Back after Close: got %v, want ErrClosed", err)
+	}
+	if _, ok := db.TryBack(); ok {
+		t.Fatal("TryBack after Close succeeded")
+	}
+	if v, changed := db.Next(); changed || v != 0 {
+		t.Fatalf("Next after Close = %d, %t; want 0, false", v, changed)
+	}
+}
+
+func TestCloseWakesBack(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Ready()
+	errc := make(chan error)
+	go func() {
+		_, err := db.Back(context.Background())
+		errc <- err
+	}()
+	db.Close()
+	if err := <-errc; !errors.Is(err, ErrClosed) {
+		t.Fatalf("blocked Back: got %v, want ErrClosed", err)
+	}
+}
+
+func TestCloseDrain(t *testing.T) {
+	db := New(0, 0)
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.Ready()
+	errc := make(chan error)
+	go func() {
+		errc <- db.CloseDrain(context.Background())
+	}()
+	select {
+	case err := <-errc:
+		t.Fatalf("CloseDrain returned %v before the pending buffer was consumed", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+	if v, changed := db.Next(); !changed || v != 1 {
+		t.Fatalf("Next during CloseDrain = %d, %t; want 1, true", v, changed)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Back(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Back after CloseDrain: got %v, want ErrClosed", err)
+	}
+}
+
+func TestCloseDrainTimeout(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Ready()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := db.CloseDrain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CloseDrain: got %v, want DeadlineExceeded", err)
+	}
+	if _, changed := db.Next(); !changed {
+		t.Fatal("pending buffer lost after CloseDrain timed out")
+	}
+}
+
+func TestWaitEmpty(t *testing.T) {
+	db := New(0, 0)
+	if err := db.WaitEmpty(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	db.Back(context.Background())
+	db.Ready()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Next()
+	}()
+	if err := db.WaitEmpty(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if db.pending() {
+		t.Fatal("WaitEmpty returned with a frame pending")
+	}
+}
+
+func TestClosedAndDrained(t *testing.T) {
+	newClosing := func() (*DoubleBuffer[int], chan error) {
+		db := New(0, 0)
+		db.Publish(context.Background(), 1)
+		errc := make(chan error, 1)
+		go func() { errc <- db.CloseDrain(context.Background()) }()
+		for !db.closed.Load() {
+			time.Sleep(time.Millisecond)
+		}
+		return db, errc
+	}
+
+	t.Run("Next", func(t *testing.T) {
+		db, errc := newClosing()
+		if v, changed := db.Next(); !changed || v != 1 {
+			t.Fatalf("Next = %d, %t; want 1, true", v, changed)
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		if v, changed := db.Next(); changed || v != 1 {
+			t.Fatalf("Next once drained = %d, %t; want 1, false", v, changed)
+		}
+	})
+	t.Run("NextErr", func(t *testing.T) {
+		db, errc := newClosing()
+		if v, changed, err := db.NextErr(); !changed || v != 1 || err != nil {
+			t.Fatalf("NextErr = %d, %t, %v; want 1, true, nil", v, changed, err)
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		if v, changed, err := db.NextErr(); changed || v != 1 || !errors.Is(err, ErrClosed) {
+			t.Fatalf("NextErr once drained = %d, %t, %v; want 1, false, ErrClosed", v, changed, err)
+		}
+	})
+	t.Run("Frames", func(t *testing.T) {
+		db, errc := newClosing()
+		var got []int
+		for v := range db.Frames(context.Background()) {
+			got = append(got, v)
+		}
+		if len(got) != 1 || got[0] != 1 {
+			t.Fatalf("Frames yielded %v, want [1]", got)
+		}
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Front", func(t *testing.T) {
+		db, errc := newClosing()
+		db.Next()
+		if err := <-errc; err != nil {
+			t.Fatal(err)
+		}
+		if v := db.Front(); v != 1 {
+			t.Fatalf("Front once drained = %d, want 1", v)
+		}
+	})
+}
+
+func TestBindContext(t *testing.T) {
+	db := New(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	db.BindContext(ctx)
+	cancel()
+	select {
+	case <-db.done:
+	case <-time.After(time.Second):
+		t.Fatal("DoubleBuffer not closed after its context was cancelled")
+	}
+
+	db = New(0, 0)
+	ctx, cancel = context.WithCancel(context.Background())
+	stop := db.BindContext(ctx)
+	if !stop() {
+		t.Fatal("stop did not unbind the context")
+	}
+	cancel()
+	if _, err := db.Back(context.Background()); err != nil {
+		t.Fatalf("Back after unbinding = %v", err)
+	}
+}
+
+func TestCloseWithError(t *testing.T) {
+	failure := errors.New("producer failed")
+	db := New(0, 0)
+	if err := db.Err(); err != nil {
+		t.Fatalf("Err before close = %v", err)
+	}
+	db.CloseWithError(failure)
+	db.Close()
+	if _, _, err := db.NextErr(); !errors.Is(err, failure) || errors.Is(err, ErrClosed) {
+		t.Fatalf("NextErr after CloseWithError = %v, want the recorded error", err)
+	}
+	if err := db.Err(); err != failure {
+		t.Fatalf("Err = %v, want %v", err, failure)
+	}
+	if _, err := db.Back(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Back after CloseWithError = %v, want ErrClosed", err)
+	}
+
+	db = New(0, 0)
+	db.Close()
+	db.CloseWithError(failure)
+	if _, _, err := db.NextErr(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("NextErr after Close then CloseWithError = %v, want ErrClosed", err)
+	}
+}
+
+// TestCloseDrainExactlyOnce races CloseDrain against a consumer using Frames
+// or NextErr, and checks that every frame readied before it, the final one
+// included, is delivered exactly once before the end of the stream.
+func TestCloseDrainExactlyOnce(t *testing.T) {
+	const frames = 10
+	for trial := 0; trial < 200; trial++ {
+		db := New(0, 0)
+		got := make(chan int, frames)
+		useFrames := trial%2 == 0
+		go func() {
+			defer close(got)
+			if useFrames {
+				for v := range db.Frames(context.Background()) {
+					got <- v
+				}
+				return
+			}
+			for {
+				v, changed, err := db.NextErr()
+				if err != nil {
+					if !errors.Is(err, ErrClosed) {
+						t.Errorf("NextErr = %v, want ErrClosed", err)
+					}
+					return
+				}
+				if changed {
+					got <- v
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+		for i := 1; i <= frames; i++ {
+			db.Publish(context.Background(), i)
+		}
+		if err := db.CloseDrain(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		seen := make(map[int]int)
+		for v := range got {
+			seen[v]++
+		}
+		for i := 1; i <= frames; i++ {
+			if seen[i] != 1 {
+				t.Fatalf("trial %d: frame %d delivered %d times, want once", trial, i, seen[i])
+			}
+		}
+	}
+}
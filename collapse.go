@@ -0,0 +1,83 @@
+package doublebuf
+
+import "time"
+
+// Collapse degrades the DoubleBuffer to a single shared buffer, a last-resort
+// knob to save memory under severe pressure. The front buffer becomes the
+// back buffer too: Back returns a pointer to the storage that Front reads,
+// Ready makes nothing pending and only bumps the generation, and Next
+// returns the front without swapping. The other buffers are zeroed, so
+// that the memory they refer to, such as the backing arrays of slices,
+// can be collected; their own storage, e.g. an array T, stays allocated. A
+// pending frame is dropped and counted by Dropped.
+//
+// In degraded mode the DoubleBuffer no longer protects readers: Front and
+// the values it returns may observe a frame while the producer is writing
+// it, so readers can see torn values, and the race detector will report
+// them. Use Expand to restore double buffering.
+//
+// Since nothing is swapped in degraded mode, consumers that wait for the
+// next frame, such as Frames, AsChannel, ConsumeDeadline and
+// FrontNewerThan, block until Expand or Close, however often the producer
+// readies; WaitFor, which only watches the front, keeps working.
+//
+// Collapse must be called by the producer, between frames: a pointer
+// previously returned by Back must not be used afterwards, call Back again
+// instead. Calling it on a collapsed or closed DoubleBuffer does nothing.
+// Collapse panics on a multi-producer DoubleBuffer.
+func (db *DoubleBuffer[T]) Collapse() {
+	db.checkSingleProducer("Collapse")
+	if db.degraded || db.closed.Load() {
+		return
+	}
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	if pending := db.next.Swap(nil); pending != nil {
+		db.drop(pending, false)
+	}
+	var zero T
+	if db.back != nil {
+		*db.back.p = zero
+		db.prev <- db.back
+	} else {
+		db.inFlight.Add(1) // the producer holds the shared buffer
+	}
+	for range len(db.prev) {
+		s := <-db.prev
+		*s.p = zero
+		db.prev <- s
+	}
+	db.back = db.front.Load()
+	db.counted = false
+	db.degraded = true
+}
+
+// Expand restores double buffering after Collapse, with b as the new back
+// buffer, so the producer's next Back returns a pointer to a copy of b. The
+// front keeps the last frame written in degraded mode. Extra buffers, see
+// WithBuffers, return to use with zero values.
+// Expand follows the concurrency rules of Collapse. Calling it on a
+// DoubleBuffer that is not collapsed does nothing.
+func (db *DoubleBuffer[T]) Expand(b T) {
+	db.checkSingleProducer("Expand")
+	if !db.degraded {
+		return
+	}
+	db.degraded = false
+	db.counted = false
+	if db.back == nil { // given up on close
+		return
+	}
+	s := <-db.prev // every buffer except the front is free
+	*s.p = b
+	db.back = s
+}
+
+// readyDegraded implements Ready in degraded mode, see Collapse.
+func (db *DoubleBuffer[T]) readyDegraded() {
+	db.readys.Add(1)
+	db.counted = false
+	db.gen.Add(1)
+	db.swapped.notify() // the front changed, wake WaitFor
+	db.tapReady(time.Time{})
+}
@@ -0,0 +1,69 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCollapse(t *testing.T) {
+	db := New([]int{1}, []int{2}, WithBuffers([]int{3}))
+	db.Collapse()
+	back, _ := db.Back(context.Background())
+	if front := db.front.Load().p; back != front {
+		t.Fatal("Back does not share the front buffer in degraded mode")
+	}
+	*back = []int{4}
+	db.Ready()
+	if v, changed := db.Next(); changed || v[0] != 4 {
+		t.Fatalf("Next in degraded mode = %v, %t; want [4], false", v, changed)
+	}
+	if g := db.Generation(); g != 1 {
+		t.Fatalf("Generation after a degraded Ready = %d, want 1", g)
+	}
+	for range len(db.prev) {
+		s := <-db.prev
+		if *s.p != nil {
+			t.Fatalf("free buffer holds %v after Collapse, want nil", *s.p)
+		}
+		db.prev <- s
+	}
+
+	db.Expand([]int{5})
+	back, _ = db.Back(context.Background())
+	if back == db.front.Load().p || (*back)[0] != 5 {
+		t.Fatalf("Back after Expand = %v, want a separate buffer holding [5]", *back)
+	}
+	db.Ready()
+	if v, changed := db.Next(); !changed || v[0] != 5 {
+		t.Fatalf("Next after Expand = %v, %t; want [5], true", v, changed)
+	}
+	if got := db.InFlight(); got != 0 {
+		t.Fatalf("InFlight = %d, want 0", got)
+	}
+}
+
+func TestCollapseReset(t *testing.T) {
+	db := New(0, 0)
+	db.Collapse()
+	db.Reset(1, 2)
+	db.Publish(context.Background(), 3)
+	if v, changed := db.Next(); !changed || v != 3 {
+		t.Fatalf("Next after Reset of a collapsed DoubleBuffer = %d, %t; want 3, true", v, changed)
+	}
+}
+
+func TestCollapseWaitFor(t *testing.T) {
+	// Reading the front while the producer writes it races in degraded
+	// mode, so check the wakeup that WaitFor waits for instead.
+	db := New(0, 0)
+	db.Collapse()
+	swapped := db.swapped.wait()
+	db.Back(context.Background())
+	db.Ready()
+	select {
+	case <-swapped:
+	case <-time.After(time.Second):
+		t.Fatal("WaitFor not woken by a Ready in degraded mode")
+	}
+}
@@ -0,0 +1,25 @@
+package doublebuf
+
+// Commit publishes the frame in the back buffer. It is equivalent to Ready,
+// and is named for producers that fill a frame transactionally, ending each
+// frame with either Commit or Abandon.
+func (db *DoubleBuffer[T]) Commit() { db.Ready() }
+
+// Abandon discards the frame being filled in the back buffer without
+// publishing it, for producers whose production failed partway. The producer
+// keeps the buffer, and the next Back returns it for a new fill. If a
+// WithOnRecycle hook is configured, Abandon runs it on the buffer, leaving it
+// in the same state as a freshly recycled one; otherwise the partial contents
+// remain and must be overwritten. The dirty set of WithDirtyMask is reset.
+// Abandon is a no-op if the producer holds no back buffer.
+// The concurrency rules of Ready apply.
+func (db *DoubleBuffer[T]) Abandon() {
+	db.checkSingleProducer("Abandon")
+	db.counted = false
+	if db.back != nil && db.dirtyMask {
+		db.back.dirty = db.back.dirty[:0]
+	}
+	if db.back != nil && db.onRecycle != nil {
+		db.invoke("OnRecycle", func() { db.onRecycle(db.back.p) })
+	}
+}
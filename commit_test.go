@@ -0,0 +1,25 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAbandon(t *testing.T) {
+	db := New(0, 0, WithOnRecycle(func(p *int) { *p = 0 }))
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.Abandon()
+	if _, changed := db.Next(); changed {
+		t.Fatal("abandoned frame was published")
+	}
+	again, _ := db.Back(context.Background())
+	if again != back || *again != 0 {
+		t.Fatalf("Back after Abandon = %d, want the same buffer reset to 0", *again)
+	}
+	*again = 2
+	db.Commit()
+	if v, changed := db.Next(); !changed || v != 2 {
+		t.Fatalf("Next after Commit = %d, %t; want 2, true", v, changed)
+	}
+}
@@ -0,0 +1,32 @@
+package doublebuf
+
+// WithCopyOnFront makes the readers of the front, i.e. Front, FrontOK,
+// FrontCopyInto, FrontAndReady, FrontIfChanged, FrontSeqlock, FrontChecked,
+// Observe, Checkpoint and Next, with its variants NextResult, TryNext and
+// FastForward, return copy(v) instead of the value v in a buffer. For a T
+// that refers to memory, such as []byte, the value returned normally shares
+// that memory with a buffer that the producer reuses after the next swap,
+// and a consumer that keeps it around sees it change underneath; copy
+// should return a deep enough copy to prevent this, e.g. bytes.Clone for
+// []byte.
+// This gives up the zero-copy handoff, and usually allocates on every read,
+// in exchange for ruling out a common aliasing bug. Readers built on these,
+// such as Frames and OnFront, return copies as well. Previous and History
+// return their own copies, and TakeFront returns a value that no buffer
+// refers to any more, so they are not affected.
+// copy runs on the goroutine of the reader, while the front may be swapped
+// concurrently, as for any reader of the front. If copy panics and
+// WithPanicHandler recovers, the reader returns the zero value of T. A nil
+// copy returns the front value itself, as without the option.
+func WithCopyOnFront[T any](copy func(T) T) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.copyFront = copy }
+}
+
+// copyOut returns the copy of v made by WithCopyOnFront, or v itself.
+func (db *DoubleBuffer[T]) copyOut(v T) (t T) {
+	if db.copyFront == nil {
+		return v
+	}
+	db.invoke("CopyOnFront", func() { t = db.copyFront(v) })
+	return t
+}
@@ -0,0 +1,55 @@
+package doublebuf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithCopyOnFront(t *testing.T) {
+	db := New([]byte("a"), []byte("b"), WithCopyOnFront(bytes.Clone))
+	front := db.Front()
+	front[0] = 'x'
+	if v := db.Front(); string(v) != "b" {
+		t.Fatalf("Front after modifying a returned value = %q, want %q", v, "b")
+	}
+	back, _ := db.Back(context.Background())
+	(*back)[0] = 'c'
+	db.Ready()
+	v, _ := db.Next()
+	v[0] = 'y'
+	if got := db.Front(); string(got) != "c" {
+		t.Fatalf("Front after modifying the value of Next = %q, want %q", got, "c")
+	}
+}
+
+func TestWithCopyOnFrontReaders(t *testing.T) {
+	db := New([]byte("a"), []byte("b"), WithCopyOnFront(bytes.Clone))
+	readers := map[string]func() []byte{
+		"FrontAndReady":  func() []byte { v, _ := db.FrontAndReady(); return v },
+		"FrontIfChanged": func() []byte { v, _, _ := db.FrontIfChanged(1); return v },
+		"FrontSeqlock":   db.FrontSeqlock,
+		"FrontChecked":   func() []byte { v, _ := db.FrontChecked(); return v },
+		"Checkpoint":     func() []byte { v, _, _ := db.Checkpoint(); return v },
+	}
+	for name, read := range readers {
+		read()[0] = 'x'
+		if v := db.Front(); string(v) != "b" {
+			t.Fatalf("Front after modifying the value of %s = %q, want %q", name, v, "b")
+		}
+	}
+}
+
+func TestWithCopyOnFrontPanic(t *testing.T) {
+	var recovered []error
+	db := New(0, 1, WithPanicHandler[int](func(err error) { recovered = append(recovered, err) }),
+		WithCopyOnFront(func(int) int { panic("boom") }))
+	if v := db.Front(); v != 0 {
+		t.Fatalf("Front with a recovered copy panic = %d, want 0", v)
+	}
+	var p *CallbackPanic
+	if len(recovered) != 1 || !errors.As(recovered[0], &p) || p.Callback != "CopyOnFront" {
+		t.Fatalf("panic handler got %v, want one CopyOnFront panic", recovered)
+	}
+}
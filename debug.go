@@ -0,0 +1,126 @@
+package doublebuf
+
+import "errors"
+
+// WithDebugChecks makes the producer methods Back, BackTimed, TryBack,
+// Ready, ReadyAt and ReadyContext detect when they are called concurrently
+// with one another, which is not allowed and would otherwise silently
+// corrupt the back buffer. The method that detects the violation panics. If
+// the two calls happen to run one after the other, nothing is detected, so
+// the guard catches most misuse under load but is no substitute for the
+// race detector.
+// The checks cost a single atomic operation per call, cheap enough to leave
+// them on in staging; without WithDebugChecks they cost nothing.
+func WithDebugChecks[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.debug = true }
+}
+
+// enterProducer marks the start of the producer method named method, and
+// panics if another one is running. It must be paired with leaveProducer.
+func (db *DoubleBuffer[T]) enterProducer(method string) {
+	if db.debug && !db.producing.CompareAndSwap(false, true) {
+		panic("doublebuf: " + method + " called concurrently with another producer method")
+	}
+}
+
+// leaveProducer marks the end of a producer method started by enterProducer.
+func (db *DoubleBuffer[T]) leaveProducer() {
+	if db.debug {
+		db.producing.Store(false)
+	}
+}
+
+// CheckedOutBack returns the back buffer the producer currently holds and
+// true, or nil and false if it holds none, i.e. after Ready until the next
+// Back; a new DoubleBuffer starts out with its first buffer checked out.
+// It helps verify while debugging that a producer writes to the buffer it
+// was handed. The pointer is the one Back returns; on a collapsed
+// DoubleBuffer it is the front buffer, see Collapse.
+// CheckedOutBack reads producer state without synchronization, so its
+// result is only meaningful when the caller knows what the producer is
+// doing, ideally when called by the producer itself; with WithDebugChecks,
+// a call that overlaps a producer method panics like one producer method
+// overlapping another. It panics on a multi-producer DoubleBuffer, whose
+// producers hold their buffers individually, see AcquireBack.
+func (db *DoubleBuffer[T]) CheckedOutBack() (*T, bool) {
+	db.checkSingleProducer("CheckedOutBack")
+	db.enterProducer("CheckedOutBack")
+	defer db.leaveProducer()
+	if db.back == nil {
+		return nil, false
+	}
+	return db.back.p, true
+}
+
+// ErrTornRead is returned by FrontChecked when the value it read does not
+// match the checksum of the frame, see WithChecksum.
+var ErrTornRead = errors.New("doublebuf: torn read of the front buffer")
+
+// WithChecksum extends the debug checks to the read path, to catch readers
+// observing a partially written buffer, e.g. while validating that a large
+// T is only read through FrontSeqlock in staging. Each swap stamps the frame
+// it promotes with sum of its contents, computed on the consumer's
+// goroutine before the frame becomes the front, and FrontChecked verifies a
+// value it reads against the stamp. sum must be deterministic and read only
+// the frame it is passed; a cheap hash of the fields that the producer
+// writes is enough. Fronts that are not made by a swap, i.e. the initial
+// front and values written by CompareAndSwapFront, TakeFront or
+// SwapBuffers, carry no stamp until the next swap, and with WithSwapFunc
+// the stamp is taken once the exchange is complete, so reads until then are
+// not checked. If sum panics and WithPanicHandler recovers, the frame
+// carries no stamp; without a handler, the frame is recycled before the
+// panic propagates.
+// Computing sum on every swap costs time proportional to the size of T, so
+// WithChecksum is meant for tests and staging, next to WithDebugChecks.
+func WithChecksum[T any](sum func(*T) uint64) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.checksum = sum }
+}
+
+// FrontChecked returns a copy of the front value, read as Front does, and
+// ErrTornRead if it does not match the checksum stamped on the frame by the
+// swap that promoted it, i.e. if the copy overlapped a write to the buffer.
+// Without WithChecksum, or for a frame without a stamp, see WithChecksum, it
+// never reports an error.
+// FrontChecked is safe to call concurrently with all other methods, but its
+// copy does race with writers when it detects a torn read.
+func (db *DoubleBuffer[T]) FrontChecked() (T, error) {
+	s := db.loadFront()
+	// Load the stamp before copying: if the slot is recycled and stamped
+	// again meanwhile, a clean copy of the older frame still matches it.
+	before := s.sum.Load()
+	t := *s.p
+	if db.checksum == nil || before == 0 {
+		return db.copyOut(t), nil
+	}
+	after := s.sum.Load()
+	if sum := db.stampSum(&t); sum != 0 && sum != before && sum != after {
+		return db.copyOut(t), ErrTornRead
+	}
+	return db.copyOut(t), nil
+}
+
+// stampSum returns the checksum of the frame in p, as stamped on slots by
+// swaps. 0 marks a slot without a stamp, and is returned if the checksum
+// panicked and the panic handler recovered.
+func (db *DoubleBuffer[T]) stampSum(p *T) uint64 {
+	var sum uint64
+	db.invoke("Checksum", func() { sum = db.checksum(p) | 1 }) // never 0
+	return sum
+}
+
+// stampNext returns the checksum of next, which has been taken from db.next
+// but not promoted yet. If the checksum panics, next is recycled before the
+// panic unwinds, as by filter. db.swapMu must be held.
+func (db *DoubleBuffer[T]) stampNext(next *slot[T]) (sum uint64) {
+	stamped := false
+	defer func() {
+		if !stamped {
+			db.readies.Store(0)
+			db.prev <- next
+			db.swapped.notify()
+		}
+	}()
+	sum = db.stampSum(next.p)
+	stamped = true
+	return sum
+}
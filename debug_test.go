@@ -0,0 +1,109 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWithDebugChecks(t *testing.T) {
+	db := New(0, 0, WithDebugChecks[int]())
+	db.Publish(context.Background(), 1) // sequential calls pass
+	errc := make(chan error)
+	go func() {
+		_, err := db.Back(context.Background()) // blocks, no buffer is free
+		errc <- err
+	}()
+	for !db.producing.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Ready concurrent with Back did not panic")
+			}
+		}()
+		db.Ready()
+	}()
+	db.Close()
+	if err := <-errc; !errors.Is(err, ErrClosed) {
+		t.Fatalf("blocked Back = %v, want ErrClosed", err)
+	}
+}
+
+func TestCheckedOutBack(t *testing.T) {
+	db := New(0, 0)
+	initial, ok := db.CheckedOutBack()
+	back, _ := db.Back(context.Background())
+	if !ok || initial != back {
+		t.Fatalf("CheckedOutBack before Back = %p, %t; want %p, true", initial, ok, back)
+	}
+	if p, ok := db.CheckedOutBack(); !ok || p != back {
+		t.Fatalf("CheckedOutBack = %p, %t; want %p, true", p, ok, back)
+	}
+	db.Ready()
+	if p, ok := db.CheckedOutBack(); ok || p != nil {
+		t.Fatalf("CheckedOutBack after Ready = %p, %t; want nil, false", p, ok)
+	}
+	db.Next()
+	back, _ = db.Back(context.Background())
+	if p, ok := db.CheckedOutBack(); !ok || p != back || p == initial {
+		t.Fatalf("CheckedOutBack after the swap = %p, %t; want the other buffer %p, true", p, ok, back)
+	}
+}
+
+func TestWithChecksum(t *testing.T) {
+	db := New(0, 0, WithChecksum(func(p *int) uint64 { return uint64(*p) * 31 }))
+	if _, err := db.FrontChecked(); err != nil {
+		t.Fatalf("FrontChecked of the unstamped initial front = %v", err)
+	}
+	db.Publish(context.Background(), 1)
+	db.Next()
+	if v, err := db.FrontChecked(); err != nil || v != 1 {
+		t.Fatalf("FrontChecked = %d, %v; want 1, nil", v, err)
+	}
+	*db.front.Load().p = 2 // a write the read path should never observe
+	if _, err := db.FrontChecked(); !errors.Is(err, ErrTornRead) {
+		t.Fatalf("FrontChecked after a write to the front = %v, want ErrTornRead", err)
+	}
+	db.TakeFront(3)
+	if _, err := db.FrontChecked(); err != nil {
+		t.Fatalf("FrontChecked after TakeFront = %v", err)
+	}
+}
+
+func TestWithChecksumPanic(t *testing.T) {
+	sum := func(p *int) uint64 {
+		if *p == 1 {
+			panic("boom")
+		}
+		return uint64(*p)
+	}
+	db := New(0, 0, WithChecksum(sum))
+	db.Publish(context.Background(), 1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panicking checksum did not propagate")
+			}
+		}()
+		db.Next()
+	}()
+	if v := db.FrontSeqlock(); v != 0 {
+		t.Fatalf("FrontSeqlock after the panic = %d, want 0", v)
+	}
+	if _, ok := db.TryBack(); !ok {
+		t.Fatal("buffer of the failed swap not recycled")
+	}
+
+	var recovered int
+	db = New(0, 0, WithChecksum(sum), WithPanicHandler[int](func(error) { recovered++ }))
+	db.Publish(context.Background(), 1)
+	if v, changed := db.Next(); !changed || v != 1 || recovered != 1 {
+		t.Fatalf("Next with a recovered checksum panic = %d, %t, %d recovered; want 1, true, 1", v, changed, recovered)
+	}
+	if _, err := db.FrontChecked(); err != nil {
+		t.Fatalf("FrontChecked of a frame without a stamp = %v", err)
+	}
+}
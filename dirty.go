@@ -0,0 +1,42 @@
+package doublebuf
+
+// Range is a half-open range [Start, End) of a frame, such as a span of
+// tiles or rows, in units chosen by the producer.
+type Range struct {
+	Start, End int
+}
+
+// WithDirtyMask attaches a dirty set to every buffer, so that a producer
+// that only rewrites parts of a frame can tell the consumer which: after
+// Back, the producer marks the ranges it touched with MarkDirty, and once
+// the frame is swapped in, FrontDirty lists them, letting the consumer skip
+// unchanged regions. The dirty set travels with its buffer through the
+// swap, and is reset when the buffer is recycled, i.e. handed to a producer
+// again by Back, TryBack or AcquireBack, and by Abandon; it therefore
+// describes a single frame. Marks of frames that are dropped, e.g. replaced
+// by a later Ready under WithBuffers, are lost with them.
+func WithDirtyMask[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.dirtyMask = true }
+}
+
+// MarkDirty adds r to the dirty set of the back buffer, see WithDirtyMask.
+// It is a no-op without WithDirtyMask, or if the producer holds no back
+// buffer. Ranges are recorded as given, so they may overlap.
+// The concurrency rules of Back apply, and MarkDirty panics on a
+// multi-producer DoubleBuffer.
+func (db *DoubleBuffer[T]) MarkDirty(r Range) {
+	db.checkSingleProducer("MarkDirty")
+	if !db.dirtyMask || db.back == nil {
+		return
+	}
+	db.back.dirty = append(db.back.dirty, r)
+}
+
+// FrontDirty returns the ranges that the producer marked dirty in the front
+// buffer, see WithDirtyMask, or nil without WithDirtyMask. Like a front
+// value of a slice type, the returned slice belongs to the buffer: it stays
+// valid until the next swap, and must not be modified.
+// FrontDirty follows the concurrency rules of Front.
+func (db *DoubleBuffer[T]) FrontDirty() []Range {
+	return db.loadFront().dirty
+}
@@ -0,0 +1,64 @@
+package doublebuf
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestWithDirtyMask(t *testing.T) {
+	db := New(0, 0, WithDirtyMask[int]())
+	db.Back(context.Background())
+	db.MarkDirty(Range{0, 2})
+	db.MarkDirty(Range{4, 5})
+	db.Ready()
+	db.MarkDirty(Range{9, 10}) // no back buffer, ignored
+	db.Next()
+	if got, want := db.FrontDirty(), []Range{{0, 2}, {4, 5}}; !slices.Equal(got, want) {
+		t.Fatalf("FrontDirty = %v, want %v", got, want)
+	}
+
+	db.Back(context.Background())
+	if got := db.FrontDirty(); len(got) != 2 {
+		t.Fatalf("FrontDirty changed when the producer took a buffer: %v", got)
+	}
+	db.MarkDirty(Range{1, 3})
+	db.Abandon()
+	db.MarkDirty(Range{2, 3})
+	db.Ready()
+	db.Next()
+	if got, want := db.FrontDirty(), []Range{{2, 3}}; !slices.Equal(got, want) {
+		t.Fatalf("FrontDirty after Abandon = %v, want %v", got, want)
+	}
+
+	// The recycled buffer starts with a clean set.
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	if got := db.FrontDirty(); len(got) != 0 {
+		t.Fatalf("FrontDirty of a recycled buffer = %v, want empty", got)
+	}
+}
+
+func TestMarkDirtyWithoutMask(t *testing.T) {
+	db := New(0, 0)
+	db.MarkDirty(Range{0, 1})
+	db.Ready()
+	db.Next()
+	if got := db.FrontDirty(); got != nil {
+		t.Fatalf("FrontDirty without WithDirtyMask = %v, want nil", got)
+	}
+}
+
+func TestWithDirtyMaskSwapFunc(t *testing.T) {
+	db := New(0, 0, WithDirtyMask[int](), WithSwapFunc(func(front, back *int) { *front, *back = *back, *front }))
+	for i, r := range []Range{{0, 1}, {2, 3}} {
+		db.Back(context.Background())
+		db.MarkDirty(r)
+		db.Ready()
+		db.Next()
+		if got, want := db.FrontDirty(), []Range{r}; !slices.Equal(got, want) {
+			t.Fatalf("swap %d: FrontDirty = %v, want %v", i+1, got, want)
+		}
+	}
+}
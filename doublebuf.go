@@ -1,60 +1,381 @@
 // Package doublebuf provides a generic double-buffering mechanism.
 // Use this for read-optimized double buffering.
+//
+// # Shutdown
+//
+// Close and CloseDrain stop producers: Back returns ErrClosed and Ready
+// becomes a no-op. Close discards a frame that is still pending, while
+// CloseDrain keeps it for the consumer. Once the DoubleBuffer is closed and
+// no readied frame is left, the consumer APIs behave as follows:
+//
+//   - Next returns the last front value with changed set to false.
+//   - NextErr returns the last front value and ErrClosed.
+//   - Frames ends its iteration.
+//   - Front keeps returning the last front value.
+//
+// A consumer draining with NextErr or Frames therefore sees every frame
+// readied before CloseDrain, then a clean end of stream. A producer that
+// fails can close with CloseWithError instead, so that consumers get its
+// error in place of ErrClosed.
 package doublebuf
 
 import (
 	"context"
+	"runtime"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // DoubleBuffer is a double buffering implementation.
-type DoubleBuffer[T comparable] struct {
-	a, b        T
-	back, front *T
-	next        atomic.Value
-	prev        chan *T
+// A DoubleBuffer must not be copied after it is created: the back and front
+// pointers refer to its own fields, so a copy would silently share buffers
+// with the original. go vet reports such copies.
+type DoubleBuffer[T any] struct {
+	_ noCopy
+
+	a, b   T
+	extra  []T        // additional buffers, see WithBuffers
+	shadow *T         // scratch buffer of the producer, see Shadow
+	slots  []*slot[T] // all backing buffers, starting with a and b
+	back   *slot[T]
+	front  atomic.Pointer[slot[T]]
+	next   atomic.Pointer[slot[T]]
+	prev   chan *slot[T]
+
+	closed    atomic.Bool
+	closeOnce sync.Once
+	done      chan struct{} // closed by Close
+	abortOnce sync.Once
+	aborted   chan struct{} // closed by Close, but not by CloseDrain
+	closeErr  error         // see CloseWithError, set before closed
+	swapped   notifier      // notified by Next after each swap
+	readied   notifier      // notified after each published frame
+	returned  notifier      // notified when a producer gives up a buffer after close
+
+	// swapMu serializes swaps and guards the state they maintain.
+	swapMu      sync.Mutex
+	previous    T // copy of the last retired front value, see WithPrevious
+	hasPrevious bool
+	gen         atomic.Uint64 // bumped whenever the front changes
+	seq         atomic.Uint64 // odd while the front is being replaced, see FrontSeqlock
+
+	epoch    time.Time    // creation time, the base of lastSwap
+	lastSwap atomic.Int64 // nanoseconds since epoch of the last swap
+	blackout atomic.Int64 // nanoseconds since epoch until which swaps are suppressed
+
+	backWait   atomic.Int64  // nanoseconds producers spent blocked in Back
+	cancelled  atomic.Uint64 // waits in Back ended by their context
+	readies    atomic.Int32  // effective Ready calls since the last swap
+	skipped    atomic.Bool   // last swap coalesced more than one Ready
+	rejected   atomic.Uint64 // frames rejected on Ready
+	swaps      atomic.Uint64 // swaps performed by Next
+	idle       atomic.Uint64 // calls to Next that did not swap
+	dropped    atomic.Uint64 // readied frames replaced before being swapped in
+	filtered   atomic.Uint64 // readied frames rejected by WithFrontFilter
+	lost       atomic.Uint64 // retired buffers dropped, see WithNextSendPolicy
+	outOfOrder atomic.Uint64 // readied frames rejected by WithSequence
+	throttled  atomic.Uint64 // readied frames dropped by WithMaxReadyRate
+	producing  atomic.Bool   // a producer method is running, see WithDebugChecks
+	yield      atomic.Bool   // see RequestYield
+	latency    *histogram    // nil unless WithLatencyHistogram
+	inFlight   atomic.Int64  // buffers held by producers
+	backs      atomic.Uint64 // effective Back calls, see BackCount
+	readys     atomic.Uint64 // published frames, see ReadyCount
+	counted    bool          // the producer's back buffer is in backs already
+
+	src         BufferSource[T] // nil unless created by NewFromSource
+	releaseOnce sync.Once
+
+	now           func() time.Time           // see WithClock
+	check         func(*T) error             // rejects frames on Ready, see WithMaxLen
+	onRecycle     func(*T)                   // see WithOnRecycle
+	onBackCancel  func(error)                // see WithOnBackCancel
+	swapFunc      func(front, back *T)       // see WithSwapFunc
+	keep          func(*T) bool              // see WithFrontFilter
+	seqOf         func(*T) uint64            // see WithSequence
+	debug         bool                       // see WithDebugChecks
+	merge         func(pending, incoming *T) // see WithMergeOnOverwrite
+	degraded      bool                       // producer only, see Collapse
+	backSpin      int                        // see WithBackSpin
+	userData      []any                      // see WithUserData
+	readyTap      func(time.Time)            // see WithReadyTap
+	history       *history[T]                // see WithHistory
+	readyPolicy   ReadyPolicy                // see WithReadyPolicy
+	dirtyMask     bool                       // see WithDirtyMask
+	onDrop        func(*T)                   // see WithOnDrop
+	checksum      func(*T) uint64            // see WithChecksum
+	rates         *rates                     // see WithRates
+	limiter       *limiter                   // see WithMaxReadyRate
+	keepPrev      bool                       // see WithPrevious
+	finalizer     bool                       // see WithFinalizer
+	cleanup       func()                     // see WithFinalizer
+	copyFront     func(T) T                  // see WithCopyOnFront
+	onPanic       func(error)                // see WithPanicHandler
+	backpressure  bool                       // see WithBackpressure
+	multiProducer bool                       // see WithMultiProducer
+	once          bool                       // close after the first Ready, see NewOnce
+	cacheAligned  bool                       // see WithCacheAligned
+	sendPolicy    SendPolicy                 // see WithNextSendPolicy
+	heldMu        sync.Mutex
+	held          map[*T]*slot[T] // buffers handed out by AcquireBack
+
+	barrier barrier[T] // see RegisterConsumer
+
+	adapt   *adaptive[T] // nil unless WithAdaptiveDepth
+	slotsMu sync.Mutex   // guards slots once an adaptive DoubleBuffer is in use
 }
 
-func New[T comparable](a, b T) *DoubleBuffer[T] {
-	db := &DoubleBuffer[T]{
-		a: a, b: b,
-		prev: make(chan *T, 1),
+// slot is a backing buffer together with the metadata of the frame it
+// holds. Slots, not bare buffers, travel through the handoff, so the
+// metadata stays attached to its frame.
+type slot[T any] struct {
+	p       *T
+	id      int           // see FrontIdentity
+	readyAt time.Time     // see ReadyAt
+	data    any           // see WithUserData
+	dirty   []Range       // see WithDirtyMask
+	sum     atomic.Uint64 // see WithChecksum, 0 if not stamped
+}
+
+// New creates a DoubleBuffer with a as the initial back buffer and b as the
+// initial front buffer.
+// The buffers are stored inside the DoubleBuffer, which must therefore never
+// be copied; use NewPtr to keep the storage elsewhere.
+func New[T any](a, b T, opts ...Option[T]) *DoubleBuffer[T] {
+	db := &DoubleBuffer[T]{a: a, b: b}
+	db.init(&db.a, &db.b, opts)
+	return db
+}
+
+// NewPtr creates a DoubleBuffer over caller-owned storage, with a as the
+// initial back buffer and b as the initial front buffer.
+// The DoubleBuffer holds only the pointers, so the storage can live
+// wherever the caller wants, e.g. in backing arrays managed elsewhere.
+// The caller must not access *a or *b other than through the DoubleBuffer
+// for as long as it is in use.
+// NewPtr panics if a or b is nil or if they point to the same value.
+func NewPtr[T any](a, b *T, opts ...Option[T]) *DoubleBuffer[T] {
+	if a == nil || b == nil {
+		panic("doublebuf: NewPtr called with a nil buffer")
+	}
+	if a == b {
+		panic("doublebuf: NewPtr called with the same buffer twice")
 	}
-	db.back = &db.a
-	db.front = &db.b
-	db.next.Store((*T)(nil))
+	db := &DoubleBuffer[T]{}
+	db.init(a, b, opts)
 	return db
 }
 
+// init sets up db with a as the initial back buffer and b as the initial
+// front buffer, then applies opts.
+func (db *DoubleBuffer[T]) init(a, b *T, opts []Option[T]) {
+	db.done = make(chan struct{})
+	db.aborted = make(chan struct{})
+	db.now = time.Now
+	for _, opt := range opts {
+		if opt != nil {
+			opt(db)
+		}
+	}
+	db.epoch = db.now()
+	inline := a == &db.a
+	if db.finalizer && inline {
+		a, b = db.detach()
+	}
+	store := make([]slot[T], 2+len(db.extra))
+	store[0].p, store[1].p = a, b
+	for i := range db.extra {
+		store[2+i].p = &db.extra[i]
+	}
+	db.slots = make([]*slot[T], len(store))
+	for i := range store {
+		store[i].id = i
+		if i < len(db.userData) {
+			store[i].data = db.userData[i]
+		}
+		db.slots[i] = &store[i]
+	}
+	if db.cacheAligned {
+		db.align(inline)
+	}
+	capacity := len(db.slots)
+	if db.adapt != nil {
+		db.adapt.init(db)
+		capacity = db.adapt.max
+	}
+	// Every buffer but the front can be free at the same time.
+	db.prev = make(chan *slot[T], capacity-1)
+	db.layout()
+	if db.finalizer {
+		runtime.SetFinalizer(db, (*DoubleBuffer[T]).finalize)
+	}
+}
+
+// layout assigns the buffers their initial roles: slots[0] is the back
+// buffer, slots[1] the front buffer and the rest are free.
+// In multi-producer mode slots[0] is free as well. db.prev must be empty.
+func (db *DoubleBuffer[T]) layout() {
+	if db.multiProducer {
+		db.back = nil
+		db.prev <- db.slots[0]
+		db.inFlight.Store(0)
+	} else {
+		db.back = db.slots[0]
+		db.inFlight.Store(1)
+	}
+	for _, s := range db.slots[2:] {
+		db.prev <- s
+	}
+	db.front.Store(db.slots[1])
+	db.next.Store(nil)
+}
+
 // Back returns the next back buffer.
 // Back will return the same value until Ready is called.
 // Back is safe to call concurrently with Next and Front.
 // Back is not safe to call concurrently with Ready.
 // Calling Back multiple times is idempotent.
+// Back returns ErrClosed once the DoubleBuffer has been closed.
 func (db *DoubleBuffer[T]) Back(ctx context.Context) (*T, error) {
+	db.checkSingleProducer("Back")
+	back, _, err := db.backTimed(ctx)
+	return back, err
+}
+
+// BackTimed is like Back, and additionally returns how long this call was
+// blocked waiting for the consumer to hand back a buffer, the same time that
+// is added to BackWaitTotal. It is 0 if a buffer was available right away,
+// so a producer can log the outliers among its waits.
+// The concurrency rules of Back apply.
+func (db *DoubleBuffer[T]) BackTimed(ctx context.Context) (*T, time.Duration, error) {
+	db.checkSingleProducer("BackTimed")
+	return db.backTimed(ctx)
+}
+
+// backTimed implements Back and BackTimed.
+func (db *DoubleBuffer[T]) backTimed(ctx context.Context) (*T, time.Duration, error) {
+	db.enterProducer("Back")
+	defer db.leaveProducer()
+	if db.closed.Load() {
+		return nil, 0, ErrClosed
+	}
+	var waited time.Duration
 	if db.back == nil { // db.back has been submitted via a previous call to ready
-		// wait for the consumer to replace the back buffer
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case db.back = <-db.prev:
+		back, d, err := db.recvBack(ctx)
+		if err != nil {
+			return nil, d, err
 		}
+		db.back, waited = back, d
+		db.checkout(back)
 	}
-	return db.back, nil
+	db.countBack()
+	return db.back.p, waited, nil
+}
+
+// recvBack receives a free buffer, waiting for the consumer to hand one back
+// if none is available, after spinning as configured by WithBackSpin. The
+// time spent blocked is returned and added to db.backWait.
+func (db *DoubleBuffer[T]) recvBack(ctx context.Context) (back *slot[T], waited time.Duration, err error) {
+	if back, ok := db.tryRecvBack(); ok {
+		return back, 0, nil
+	}
+	start := db.now()
+	defer func() {
+		waited = db.now().Sub(start)
+		db.backWait.Add(int64(waited))
+	}()
+	for range db.backSpin { // see WithBackSpin
+		runtime.Gosched()
+		if back, ok := db.tryRecvBack(); ok {
+			return back, 0, nil
+		}
+	}
+	select {
+	case <-ctx.Done():
+		err := ctx.Err()
+		db.cancelled.Add(1)
+		if db.onBackCancel != nil {
+			db.invoke("OnBackCancel", func() { db.onBackCancel(err) })
+		}
+		return nil, 0, err
+	case <-db.done:
+		return nil, 0, ErrClosed
+	case back := <-db.prev:
+		return back, 0, nil
+	}
+}
+
+// tryRecvBack receives a free buffer if one is available without blocking.
+func (db *DoubleBuffer[T]) tryRecvBack() (*slot[T], bool) {
+	select {
+	case back := <-db.prev:
+		if db.adapt != nil {
+			db.maybeShrink()
+		}
+		return back, true
+	default:
+	}
+	if db.adapt != nil {
+		return db.grow()
+	}
+	return nil, false
 }
 
 // TryBack returns the next back buffer if it is ready.
 // It does not block.
+// TryBack always fails once the DoubleBuffer has been closed.
 func (db *DoubleBuffer[T]) TryBack() (t *T, ok bool) {
+	db.checkSingleProducer("TryBack")
+	db.enterProducer("TryBack")
+	defer db.leaveProducer()
+	if db.closed.Load() {
+		return nil, false
+	}
 	if db.back == nil { // db.back has been submitted via a previous call to ready
-		// wait for the consumer to replace the back buffer
-		select {
-		case db.back = <-db.prev:
-		default:
+		back, ok := db.tryRecvBack()
+		if !ok {
 			return nil, false
 		}
+		db.back = back
+		db.checkout(back)
 	}
-	return db.back, true
+	db.countBack()
+	return db.back.p, true
+}
+
+// checkout hands a buffer received from the free list over to a producer.
+// The caller must have recorded the producer's ownership of s already, so
+// that a panicking recycle hook does not lose the buffer.
+func (db *DoubleBuffer[T]) checkout(s *slot[T]) {
+	db.inFlight.Add(1)
+	if db.dirtyMask {
+		s.dirty = s.dirty[:0]
+	}
+	if db.onRecycle != nil {
+		db.invoke("OnRecycle", func() { db.onRecycle(s.p) })
+	}
+}
+
+// countBack counts the producer's back buffer in BackCount the first time it
+// is returned for a new frame.
+func (db *DoubleBuffer[T]) countBack() {
+	if !db.counted {
+		db.counted = true
+		db.backs.Add(1)
+	}
+}
+
+// BackWouldBlock reports whether a call to Back would currently block, i.e.
+// the producer holds no back buffer and none is free. It has no side effects,
+// so a producer can use it to decide between calling Back and doing other
+// work. A false result stays valid, since only the producer takes buffers,
+// but a true result may change as soon as BackWouldBlock returns, since the
+// consumer can free a buffer at any time.
+// BackWouldBlock follows the concurrency rules of Back.
+func (db *DoubleBuffer[T]) BackWouldBlock() bool {
+	db.checkSingleProducer("BackWouldBlock")
+	return !db.closed.Load() && db.back == nil && len(db.prev) == 0
 }
 
 // Ready is used to signal that the back buffer is ready to be swapped with
@@ -62,15 +383,209 @@ func (db *DoubleBuffer[T]) TryBack() (t *T, ok bool) {
 // It is safe to call Ready concurrently with Next and Front.
 // It is not safe to call Ready concurrently with Back.
 // Calling Ready multiple times is idempotent.
+// Ready is a no-op once the DoubleBuffer has been closed.
+// A frame rejected by WithMaxLen or throttled by WithMaxReadyRate is not
+// published; the producer keeps the back buffer, so the next Back returns
+// it again.
+// In backpressure mode Ready may block, see ReadyContext.
 func (db *DoubleBuffer[T]) Ready() {
-	if db.back != nil {
-		db.next.Store(db.back)
-		db.back = nil
+	db.checkSingleProducer("Ready")
+	db.ready(context.Background(), time.Time{}, false)
+}
+
+// ReadyAt is like Ready, but stamps the frame with the current time, as
+// read from the clock of WithClock. The stamp travels with the frame and is
+// reported by NextResult once the frame is swapped in, so the consumer can
+// measure the latency from production to consumption without a timestamp
+// field in T. Frames readied by Ready carry no stamp.
+// The concurrency rules of Ready apply.
+func (db *DoubleBuffer[T]) ReadyAt() {
+	db.checkSingleProducer("ReadyAt")
+	db.ready(context.Background(), db.now(), false)
+}
+
+// Publish stores v in the back buffer and readies it, combining Back and
+// Ready for producers that already have the value computed. It returns the
+// error of Back or ReadyContext, if any.
+// Publish copies v into the back buffer. For pointer, slice or map types
+// only the reference is copied: the consumer sees data shared with the
+// caller, and the storage the back buffer previously referenced is not
+// reused.
+// The concurrency rules of Back and Ready apply.
+func (db *DoubleBuffer[T]) Publish(ctx context.Context, v T) error {
+	back, err := db.Back(ctx)
+	if err != nil {
+		return err
+	}
+	*back = v
+	return db.ReadyContext(ctx)
+}
+
+// ready implements Ready, ReadyAt and ReadyContext, stamping the frame with
+// readyAt.
+func (db *DoubleBuffer[T]) ready(ctx context.Context, readyAt time.Time, try bool) error {
+	db.enterProducer("Ready")
+	defer db.leaveProducer()
+	if db.closed.Load() {
+		db.giveUpBack()
+		return ErrClosed
 	}
+	if db.back == nil {
+		return nil
+	}
+	if db.degraded {
+		db.readyDegraded()
+		return nil
+	}
+	if err := db.validate(db.back.p); err != nil {
+		return err
+	}
+	if db.throttle() {
+		return nil
+	}
+	db.back.readyAt = db.stamp(readyAt)
+	readyAt = db.back.readyAt
+	old, err := db.publish(ctx, db.back, try)
+	if err != nil {
+		if err == ErrClosed {
+			db.giveUpBack()
+		}
+		return err
+	}
+	db.readys.Add(1)
+	db.back = nil
+	db.counted = false
+	db.inFlight.Add(-1)
+	if old != nil {
+		// Only once the producer has let go of its buffer, so a panicking
+		// hook cannot leave it checked out while it is pending.
+		defer db.drop(old, false)
+	}
+	if db.once {
+		db.shutdown(nil)
+	}
+	db.tapReady(readyAt)
+	return nil
+}
+
+// publish makes s the buffer promoted by the next swap.
+// A pending buffer that s replaces is returned as old, for the caller to
+// drop once it has settled its own state, unless in backpressure mode,
+// where publish waits for it to be consumed instead.
+func (db *DoubleBuffer[T]) publish(ctx context.Context, s *slot[T], try bool) (old *slot[T], err error) {
+	if db.backpressure {
+		if try {
+			return nil, db.publishTry(s)
+		}
+		return nil, db.publishWait(ctx, s)
+	}
+	// Count before publishing, so the swap that consumes this buffer sees it.
+	db.readies.Add(1)
+	if db.merge != nil {
+		db.publishMerge(s)
+	} else if db.readyPolicy == FirstWins {
+		if !db.next.CompareAndSwap(nil, s) {
+			if try {
+				db.readies.Add(-1)
+				return nil, ErrFull
+			}
+			db.drop(s, true)
+			return nil, nil
+		}
+	} else {
+		old = db.next.Swap(s)
+	}
+	db.readied.notify()
+	return old, nil
+}
+
+// drop discards the readied frame in s, counting it in Dropped, and returns
+// its buffer to the free list after running the hook of WithOnDrop. If the
+// hook panics, the buffer is returned all the same, unless owned is set, for
+// a frame that the producer was readying: the producer then keeps it.
+func (db *DoubleBuffer[T]) drop(s *slot[T], owned bool) {
+	db.dropped.Add(1)
+	if db.onDrop != nil {
+		returned := false
+		defer func() {
+			if !returned && !owned {
+				db.prev <- s
+			}
+		}()
+		db.invoke("OnDrop", func() { db.onDrop(s.p) })
+		returned = true
+	}
+	db.prev <- s
 }
 
 // Front returns the front buffer.
-func (db *DoubleBuffer[T]) Front() T { return *db.front }
+// On a DoubleBuffer that was not created by one of the constructors, such as
+// the zero value, Front returns the zero value of T; see FrontOK. The other
+// readers of the front, such as FrontSeqlock, Observe or FrontUserData,
+// likewise report a zero front there, in a slot of -1 for FrontIdentity.
+func (db *DoubleBuffer[T]) Front() T {
+	t, _ := db.FrontOK()
+	return t
+}
+
+// FrontOK returns the front buffer, with ok set to false if the DoubleBuffer
+// was not created by one of the constructors, e.g. when it is embedded in a
+// struct and New was never called.
+func (db *DoubleBuffer[T]) FrontOK() (t T, ok bool) {
+	front := db.front.Load()
+	if front == nil {
+		return t, false
+	}
+	return db.copyOut(*front.p), true
+}
+
+// loadFront returns the front slot or, on a DoubleBuffer that was not
+// created by one of the constructors, a slot holding the zero value with an
+// id of -1, so readers of the front never dereference nil.
+func (db *DoubleBuffer[T]) loadFront() *slot[T] {
+	if s := db.front.Load(); s != nil {
+		return s
+	}
+	return &slot[T]{p: new(T), id: -1}
+}
+
+// FrontCopyInto copies the front buffer into dst.
+// It is equivalent to *dst = db.Front(), but avoids the intermediate copy,
+// which matters for callers reusing a scratch value of a large T. Like
+// Front, it stores the zero value of T on a DoubleBuffer that was not
+// created by one of the constructors, and it stores the copy made by
+// WithCopyOnFront if that option is given, which brings back the
+// intermediate copy.
+// FrontCopyInto is safe to call concurrently with Next.
+func (db *DoubleBuffer[T]) FrontCopyInto(dst *T) {
+	front := db.front.Load()
+	switch {
+	case front == nil:
+		var zero T
+		*dst = zero
+	case db.copyFront != nil:
+		*dst = db.copyOut(*front.p)
+	default:
+		*dst = *front.p
+	}
+}
+
+// FrontAndReady returns the front buffer together with whether a readied
+// frame is pending, i.e. whether the next call to Next would swap.
+// Both are read as one snapshot: if a swap happens while they are being
+// read, FrontAndReady retries, so the result never pairs a front with the
+// readiness of another generation. The snapshot may of course be outdated
+// by the time the caller acts on it.
+// FrontAndReady is safe to call concurrently with Next.
+func (db *DoubleBuffer[T]) FrontAndReady() (t T, ready bool) {
+	for {
+		gen := db.gen.Load()
+		t, ready = *db.loadFront().p, db.pending()
+		if db.gen.Load() == gen {
+			return db.copyOut(t), ready
+		}
+	}
+}
 
 // Next swaps the front and back buffers and returns the new front buffer
 // if the back buffer is ready to be used. Otherwise, it returns the
@@ -79,14 +594,179 @@ func (db *DoubleBuffer[T]) Front() T { return *db.front }
 // It is safe to call Next concurrently, however, an old reference to the
 // front buffer is no longer guaranteed to be valid if Next returns with changed set to true.
 func (db *DoubleBuffer[T]) Next() (t T, changed bool) {
+	r := db.advance()
+	return r.Value, r.Changed
+}
+
+// NextResult is the outcome of a call to Next, as returned by
+// DoubleBuffer.NextResult.
+type NextResult[T any] struct {
+	Value   T         // the front buffer after the call
+	Changed bool      // whether the front buffer was swapped
+	Skipped int       // readied frames coalesced into the swap, see FastForward
+	ReadyAt time.Time // when the new front was readied by ReadyAt, if stamped
+	Err     error     // ErrRetireFull if the retired buffer was lost, see WithNextSendPolicy
+}
+
+// NextResult is like Next, but reports everything known about the swap in a
+// single NextResult. ReadyAt is the zero time unless the call swapped in a
+// frame readied by ReadyAt; subtracting it from the current time gives the
+// latency of that frame.
+func (db *DoubleBuffer[T]) NextResult() NextResult[T] { return db.advance() }
+
+// TryNext is like Next, and additionally reports in backFree whether a
+// producer currently has a buffer to write into, either one it already holds
+// or a free one that Back would return without blocking.
+// It lets a coordinator see the state of both sides in one call; like any
+// such snapshot, backFree may change as soon as TryNext returns.
+func (db *DoubleBuffer[T]) TryNext() (t T, changed bool, backFree bool) {
+	t, changed = db.Next()
+	return t, changed, db.inFlight.Load() > 0 || len(db.prev) > 0
+}
+
+// advance implements Next and its variants.
+func (db *DoubleBuffer[T]) advance() NextResult[T] {
+	r := db.swap(true)
+	r.Value = db.copyOut(r.Value)
+	return r
+}
+
+// swap swaps in the pending frame, if any. The result only carries the
+// front value if value is set, see NextChanged.
+func (db *DoubleBuffer[T]) swap(value bool) NextResult[T] {
 	// The sequence:
 	// 1. Check if a new buffer is ready.
 	// 2. If not, return the current front buffer.
-	// 3. If so, make the new buffer available for swapping.
-	next := db.next.Swap((*T)(nil)).(*T)
-	if next != nil {
-		db.prev <- db.front
-		db.front = next
+	// 3. If so, publish it as the front buffer and hand the old front back
+	//    to the producer. Publishing first ensures a concurrent Next never
+	//    retires a buffer the producer already owns.
+	if db.next.Load() == nil { // fast path, nothing to swap
+		db.idle.Add(1)
+		return NextResult[T]{Value: db.frontValue(value)}
+	}
+	if db.blackout.Load() != 0 && db.blackoutLeft() > 0 { // see BlackoutUntil
+		db.idle.Add(1)
+		return NextResult[T]{Value: db.frontValue(value)}
+	}
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	next := db.next.Swap(nil)
+	if next == nil { // a concurrent Next got there first
+		db.idle.Add(1)
+		return NextResult[T]{Value: db.frontValue(value)}
+	}
+	if (db.keep != nil || db.seqOf != nil) && !db.filter(next) {
+		return NextResult[T]{Value: db.frontValue(value)}
+	}
+	var sum uint64 // see WithChecksum
+	if db.checksum != nil && db.swapFunc == nil {
+		sum = db.stampNext(next)
+	}
+	readies := int(db.readies.Swap(0))
+	if readies < 1 { // backpressure mode does not count readies
+		readies = 1
+	}
+	db.skipped.Store(readies > 1)
+	db.seq.Add(1)
+	if db.checksum != nil {
+		if db.swapFunc == nil {
+			next.sum.Store(sum)
+		} else {
+			db.front.Load().sum.Store(0) // stamped once the swap is complete
+		}
+	}
+	front, old, ok := db.exchange(next)
+	if !ok {
+		return NextResult[T]{Value: db.frontValue(value)}
+	}
+	db.gen.Add(1)
+	db.swaps.Add(1)
+	now := db.now()
+	db.lastSwap.Store(int64(now.Sub(db.epoch)))
+	if db.rates != nil {
+		db.rates.consume.tick(int64(now.Sub(db.epoch)))
+	}
+	if db.latency != nil && !front.readyAt.IsZero() {
+		db.latency.record(now.Sub(front.readyAt))
 	}
-	return *db.front, next != nil
+	if db.keepPrev {
+		// Copy the retired value before the producer can reuse its buffer.
+		db.previous, db.hasPrevious = *old.p, true
+	}
+	if db.history != nil {
+		db.history.record(*front.p)
+	}
+	err := db.retire(old)
+	db.seq.Add(1)
+	db.swapped.notify()
+	if db.checksum != nil && db.swapFunc != nil {
+		// Outside the seqlock section, so a panicking checksum leaves a
+		// complete swap behind; nothing but swaps writes the front.
+		front.sum.Store(db.stampSum(front.p))
+	}
+	return NextResult[T]{Value: db.frontValue(value), Changed: true, Skipped: readies - 1, ReadyAt: front.readyAt, Err: err}
+}
+
+// frontValue returns a copy of the front value if want is set, and the zero
+// value otherwise.
+func (db *DoubleBuffer[T]) frontValue(want bool) (t T) {
+	if want {
+		t = *db.loadFront().p
+	}
+	return t
+}
+
+// NextChanged swaps in the readied frame like Next, and reports whether it
+// did, without copying the front value, for a large T where the consumer
+// only sometimes needs the value, or reads part of it: it can then fetch
+// it lazily, e.g. with Front, FrontCopyInto or FrontSeqlock. WithCopyOnFront
+// does not apply, since no value is returned.
+// NextChanged follows the concurrency rules of Next.
+func (db *DoubleBuffer[T]) NextChanged() bool {
+	return db.swap(false).Changed
 }
+
+// Skipped reports whether the most recent swap coalesced intermediate frames,
+// that is, whether Ready was called more than once between the previous swap
+// and that one, so that some readied frames were never promoted to the front.
+// It is meant to be called by the consumer right after a Next that returned
+// changed set to true.
+// A producer using only the two buffers of New cannot ready a second frame
+// before the consumer swaps, so frames are only skipped when extra buffers
+// are configured with WithBuffers.
+func (db *DoubleBuffer[T]) Skipped() bool { return db.skipped.Load() }
+
+// FastForward promotes the most recently readied buffer to the front, like
+// Next, and additionally reports how many readied frames were skipped since
+// the previous swap. With WithBuffers, a Ready that replaces a pending frame
+// recycles its buffer immediately, so a single FastForward always catches up
+// to the newest frame; skipped counts the frames recycled that way, which
+// WithOnDrop can clean up.
+// changed is false, and skipped 0, if nothing was ready.
+func (db *DoubleBuffer[T]) FastForward() (t T, skipped int, changed bool) {
+	r := db.advance()
+	return r.Value, r.Skipped, r.Changed
+}
+
+// Previous returns a copy of the front value retired by the most recent swap,
+// giving the consumer a one-frame history, e.g. to interpolate between frames.
+// The copy is taken at swap time, so it stays valid regardless of how the
+// retired buffer is reused. ok is false until the first swap, and always
+// without WithPrevious.
+// Previous is safe to call concurrently with Next.
+func (db *DoubleBuffer[T]) Previous() (v T, ok bool) {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	return db.previous, db.hasPrevious
+}
+
+// noCopy may be embedded into structs which must not be copied after first
+// use. go vet's copylocks check reports copies of values containing it.
+// See https://golang.org/issues/8005#issuecomment-190753527.
+type noCopy struct{}
+
+// Lock is a no-op used by go vet's copylocks check.
+func (*noCopy) Lock() {}
+
+// Unlock is a no-op used by go vet's copylocks check.
+func (*noCopy) Unlock() {}
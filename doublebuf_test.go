@@ -2,10 +2,375 @@ package doublebuf
 
 import (
 	"context"
+	"fmt"
+	"runtime"
+	"slices"
 	"testing"
 	"time"
 )
 
+func TestFrontZeroValue(t *testing.T) {
+	var db DoubleBuffer[int]
+	if v := db.Front(); v != 0 {
+		t.Fatalf("Front of the zero value = %d, want 0", v)
+	}
+	if _, ok := db.FrontOK(); ok {
+		t.Fatal("FrontOK of the zero value reported ok")
+	}
+	v1, _ := db.FrontAndReady()
+	v2, _, _ := db.FrontIfChanged(1)
+	v3, _, _ := db.Observe()
+	v4, _ := db.FrontChecked()
+	if v1 != 0 || v2 != 0 || v3 != 0 || v4 != 0 || db.FrontSeqlock() != 0 {
+		t.Fatal("a front reader of the zero value returned a value")
+	}
+	if slot, _ := db.FrontIdentity(); slot != -1 || db.FrontIsA() {
+		t.Fatalf("FrontIdentity of the zero value = %d, FrontIsA = %t; want -1, false", slot, db.FrontIsA())
+	}
+	if db.FrontDirty() != nil || db.FrontUserData() != nil {
+		t.Fatal("FrontDirty or FrontUserData of the zero value is not nil")
+	}
+	if v, ok := New(0, 1).FrontOK(); !ok || v != 1 {
+		t.Fatalf("FrontOK = %d, %t; want 1, true", v, ok)
+	}
+}
+
+func TestFrontCopyInto(t *testing.T) {
+	db := New(0, 1)
+	var dst int
+	db.FrontCopyInto(&dst)
+	if dst != 1 {
+		t.Fatalf("FrontCopyInto = %d, want 1", dst)
+	}
+	back, _ := db.Back(context.Background())
+	*back = 2
+	db.Ready()
+	db.Next()
+	db.FrontCopyInto(&dst)
+	if dst != 2 {
+		t.Fatalf("FrontCopyInto after swap = %d, want 2", dst)
+	}
+
+	var zero DoubleBuffer[int]
+	zero.FrontCopyInto(&dst)
+	if dst != 0 {
+		t.Fatalf("FrontCopyInto of the zero DoubleBuffer = %d, want 0", dst)
+	}
+
+	db = New(0, 1, WithCopyOnFront(func(v int) int { return v * 10 }))
+	db.FrontCopyInto(&dst)
+	if dst != 10 {
+		t.Fatalf("FrontCopyInto with WithCopyOnFront = %d, want the copy 10", dst)
+	}
+}
+
+func TestFrontAndReady(t *testing.T) {
+	db := New(0, 1)
+	if v, ready := db.FrontAndReady(); v != 1 || ready {
+		t.Fatalf("FrontAndReady = %d, %t; want 1, false", v, ready)
+	}
+	back, _ := db.Back(context.Background())
+	*back = 2
+	db.Ready()
+	if v, ready := db.FrontAndReady(); v != 1 || !ready {
+		t.Fatalf("FrontAndReady = %d, %t; want 1, true", v, ready)
+	}
+	db.Next()
+	if v, ready := db.FrontAndReady(); v != 2 || ready {
+		t.Fatalf("FrontAndReady = %d, %t; want 2, false", v, ready)
+	}
+}
+
+func TestTryNext(t *testing.T) {
+	db := New(0, 0)
+	if _, changed, backFree := db.TryNext(); changed || !backFree {
+		t.Fatalf("TryNext = %t, %t; want false, true", changed, backFree)
+	}
+	db.Back(context.Background())
+	db.Ready()
+	if _, changed, backFree := db.TryNext(); !changed || !backFree {
+		t.Fatalf("TryNext = %t, %t; want true, true", changed, backFree)
+	}
+}
+
+func TestBackWouldBlock(t *testing.T) {
+	db := New(0, 0)
+	if db.BackWouldBlock() {
+		t.Fatal("BackWouldBlock while holding the back buffer")
+	}
+	db.Back(context.Background())
+	db.Ready()
+	if !db.BackWouldBlock() {
+		t.Fatal("BackWouldBlock = false with no free buffer")
+	}
+	db.Next()
+	if db.BackWouldBlock() {
+		t.Fatal("BackWouldBlock after the consumer freed a buffer")
+	}
+}
+
+func TestWithOnRecycle(t *testing.T) {
+	var recycled []int
+	db := New(0, 0, WithOnRecycle(func(p *int) {
+		recycled = append(recycled, *p)
+		*p = -1
+	}))
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.Ready()
+	back, _ = db.TryBack()
+	if back != nil || len(recycled) != 0 {
+		t.Fatal("recycled before the consumer freed a buffer")
+	}
+	db.Next()
+	back, _ = db.Back(context.Background())
+	if len(recycled) != 1 || recycled[0] != 0 || *back != -1 {
+		t.Fatalf("recycled %v, back %d; want [0], -1", recycled, *back)
+	}
+	db.Back(context.Background())
+	if len(recycled) != 1 {
+		t.Fatal("recycled again on a repeated Back")
+	}
+}
+
+func TestPrewarm(t *testing.T) {
+	for _, db := range []*DoubleBuffer[int]{
+		New(1, 2),
+		New(1, 2, WithBuffers(3)),
+	} {
+		db.Prewarm()
+		if v, changed := db.Next(); changed || v != 2 {
+			t.Fatalf("Next after Prewarm = %d, %t; want 2, false", v, changed)
+		}
+		if g := db.Generation(); g != 0 {
+			t.Fatalf("Generation after Prewarm = %d, want 0", g)
+		}
+		if back, _ := db.TryBack(); *back != 1 {
+			t.Fatalf("Back after Prewarm = %d, want 1", *back)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	db := New(0, 0)
+	if err := db.Publish(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if v, changed := db.Next(); !changed || v != 1 {
+		t.Fatalf("Next = %d, %t; want 1, true", v, changed)
+	}
+	db.Close()
+	if err := db.Publish(context.Background(), 2); err != ErrClosed {
+		t.Fatalf("Publish after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestSkipped(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Ready()
+	db.Ready() // idempotent, not a second frame
+	if _, changed := db.Next(); !changed {
+		t.Fatal("Next did not swap")
+	}
+	if db.Skipped() {
+		t.Fatal("Skipped after a single Ready")
+	}
+}
+
+func TestWithBuffers(t *testing.T) {
+	db := New(0, 0, WithBuffers(0))
+	for i := 1; i <= 3; i++ {
+		back, ok := db.TryBack()
+		if !ok {
+			t.Fatalf("TryBack %d blocked with a third buffer", i)
+		}
+		*back = i
+		db.Ready()
+	}
+	if v, changed := db.Next(); !changed || v != 3 {
+		t.Fatalf("Next = %d, %t; want 3, true", v, changed)
+	}
+	if !db.Skipped() {
+		t.Fatal("Skipped = false after three Ready calls")
+	}
+}
+
+func TestFastForward(t *testing.T) {
+	db := New(0, 0, WithBuffers(0, 0))
+	if _, skipped, changed := db.FastForward(); changed || skipped != 0 {
+		t.Fatalf("FastForward with nothing ready = %d, %t; want 0, false", skipped, changed)
+	}
+	for i := 1; i <= 4; i++ {
+		back, _ := db.TryBack()
+		*back = i
+		db.Ready()
+	}
+	if v, skipped, changed := db.FastForward(); v != 4 || skipped != 3 || !changed {
+		t.Fatalf("FastForward = %d, %d, %t; want 4, 3, true", v, skipped, changed)
+	}
+}
+
+func TestWithOnDrop(t *testing.T) {
+	for _, tt := range []struct {
+		policy ReadyPolicy
+		front  int
+		drop   []int
+	}{
+		{LastWins, 3, []int{1, 2}},
+		{FirstWins, 1, []int{2, 3}},
+	} {
+		var dropped []int
+		db := New(0, 0, WithBuffers(0, 0), WithReadyPolicy[int](tt.policy),
+			WithOnDrop(func(p *int) { dropped = append(dropped, *p) }))
+		for i := 1; i <= 3; i++ {
+			db.Publish(context.Background(), i)
+		}
+		if v, skipped, _ := db.FastForward(); v != tt.front || skipped != 2 {
+			t.Fatalf("policy %d: FastForward = %d, %d; want %d, 2", tt.policy, v, skipped, tt.front)
+		}
+		if !slices.Equal(dropped, tt.drop) {
+			t.Fatalf("policy %d: dropped %v, want %v", tt.policy, dropped, tt.drop)
+		}
+	}
+}
+
+// TestWithOnDropPanic checks that a drop hook panicking without a
+// WithPanicHandler leaves the producer without a buffer that is also pending.
+func TestWithOnDropPanic(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithOnDrop(func(*int) { panic("boom") }))
+	db.Publish(context.Background(), 1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("panicking drop hook did not propagate")
+			}
+		}()
+		db.Publish(context.Background(), 2) // drops 1
+	}()
+	if p, ok := db.CheckedOutBack(); ok {
+		t.Fatalf("producer still holds %p after the panic", p)
+	}
+	if n := db.InFlight(); n != 0 {
+		t.Fatalf("InFlight after the panic = %d, want 0", n)
+	}
+	back, err := db.Back(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	*back = 3 // must not reach the pending frame
+	if v, changed := db.Next(); !changed || v != 2 {
+		t.Fatalf("Next = %d, %t; want 2, true", v, changed)
+	}
+}
+
+func TestReadyAt(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now))
+	if r := db.NextResult(); r.Changed || !r.ReadyAt.IsZero() {
+		t.Fatalf("NextResult with nothing ready = %+v", r)
+	}
+	clock.advance(time.Second)
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.ReadyAt()
+	clock.advance(time.Second)
+	r := db.NextResult()
+	if !r.Changed || r.Value != 1 || r.Skipped != 0 {
+		t.Fatalf("NextResult = %+v; want 1, changed", r)
+	}
+	if want := time.Unix(1, 0); !r.ReadyAt.Equal(want) {
+		t.Fatalf("ReadyAt = %v, want %v", r.ReadyAt, want)
+	}
+
+	// The stamp belongs to the frame, not to the buffer that carried it.
+	back, _ = db.Back(context.Background())
+	*back = 2
+	db.Ready()
+	if r := db.NextResult(); !r.Changed || !r.ReadyAt.IsZero() {
+		t.Fatalf("NextResult after Ready = %+v; want no stamp", r)
+	}
+}
+
+func TestWithReadyTap(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	var taps []time.Time
+	db := New(0, 0, WithBuffers(0), WithClock[int](clock.now),
+		WithReadyTap[int](func(readyAt time.Time) { taps = append(taps, readyAt) }))
+	db.Ready()
+	clock.advance(time.Second)
+	db.TryBack()
+	db.Ready() // drops the first frame, still tapped
+	db.Next()
+	db.Back(context.Background())
+	clock.advance(time.Second)
+	db.ReadyAt()
+	db.Close()
+	db.Ready() // after close, not tapped
+	want := []time.Time{time.Unix(0, 0), time.Unix(1, 0), time.Unix(2, 0)}
+	if len(taps) != len(want) {
+		t.Fatalf("tapped %v, want %v", taps, want)
+	}
+	for i := range want {
+		if !taps[i].Equal(want[i]) {
+			t.Fatalf("tapped %v, want %v", taps, want)
+		}
+	}
+}
+
+func TestPrevious(t *testing.T) {
+	db := New(0, 1)
+	db.Publish(context.Background(), 2)
+	db.Next()
+	if _, ok := db.Previous(); ok {
+		t.Fatal("Previous ok without WithPrevious")
+	}
+
+	db = New(0, 1, WithPrevious[int]())
+	if _, ok := db.Previous(); ok {
+		t.Fatal("Previous ok before the first swap")
+	}
+	for i := 2; i <= 3; i++ {
+		back, _ := db.Back(context.Background())
+		*back = i
+		db.Ready()
+		db.Next()
+		if v, ok := db.Previous(); !ok || v != i-1 {
+			t.Fatalf("Previous = %d, %t; want %d, true", v, ok, i-1)
+		}
+	}
+}
+
+func TestNewPtr(t *testing.T) {
+	a, b := 0, 1
+	db := NewPtr(&a, &b)
+	back, _ := db.Back(context.Background())
+	if back != &a {
+		t.Fatal("Back does not return the caller's buffer")
+	}
+	*back = 2
+	db.Ready()
+	if v, _ := db.Next(); v != 2 || a != 2 {
+		t.Fatalf("Next = %d, a = %d; want 2, 2", v, a)
+	}
+}
+
+// TestBuffersLiveInStruct documents why a DoubleBuffer must not be copied:
+// New stores the buffers in the struct itself, so the pointers handed out
+// refer to the original's fields, and a copy made with
+// cp := *db would hand out pointers into db, not into cp.
+// go vet reports such copies thanks to the embedded noCopy.
+func TestBuffersLiveInStruct(t *testing.T) {
+	db := New(0, 0)
+	back, _ := db.Back(context.Background())
+	if back != &db.a {
+		t.Fatal("Back does not point into the DoubleBuffer")
+	}
+	if db.front.Load().p != &db.b {
+		t.Fatal("front does not point into the DoubleBuffer")
+	}
+}
+
 func BenchmarkDoubleBuffer(b *testing.B) {
 	b.Run("Next", func(b *testing.B) {
 		db := New(0, 0)
@@ -42,6 +407,21 @@ func BenchmarkDoubleBuffer(b *testing.B) {
 			}
 		})
 	})
+	// Cycle measures a full Back/Ready/Next handoff on a single goroutine.
+	// It must report 0 allocs/op: the handoff only moves pointers through
+	// atomic.Pointer and the preallocated free list.
+	b.Run("Cycle", func(b *testing.B) {
+		db := New(0, 0)
+		ctx := context.Background()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			back, _ := db.Back(ctx)
+			*back = i
+			db.Ready()
+			db.Next()
+		}
+	})
 	b.Run("Back", func(b *testing.B) {
 		db := New(0, 0)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
@@ -70,3 +450,108 @@ func BenchmarkDoubleBuffer(b *testing.B) {
 		})
 	})
 }
+
+func TestWithBackSpin(t *testing.T) {
+	db := New(0, 0, WithBackSpin[int](100))
+	db.Publish(context.Background(), 1)
+	go db.Next()
+	if _, err := db.Back(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	db.Ready()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := db.Back(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Back with no free buffer after spinning = %v, want DeadlineExceeded", err)
+	}
+}
+
+func BenchmarkBackSpin(b *testing.B) {
+	for _, n := range []int{0, 16, 256} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			db := New(0, 0, WithBackSpin[int](n))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// eater goroutine
+			go func() {
+				for ctx.Err() == nil {
+					if _, changed := db.Next(); !changed {
+						runtime.Gosched()
+					}
+				}
+			}()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				back, _ := db.Back(ctx)
+				*back = i
+				db.Ready()
+			}
+		})
+	}
+}
+
+func TestNextChanged(t *testing.T) {
+	db := New(0, 0)
+	if db.NextChanged() {
+		t.Fatal("NextChanged with nothing ready = true")
+	}
+	db.Publish(context.Background(), 1)
+	if !db.NextChanged() || db.Front() != 1 {
+		t.Fatalf("NextChanged did not swap in the frame, Front = %d", db.Front())
+	}
+}
+
+// BenchmarkNextChanged compares a full handoff of a large T consumed with
+// Next, which copies the front value, and with NextChanged, which does not.
+func BenchmarkNextChanged(b *testing.B) {
+	type frame [16 << 10]byte
+	cycle := func(b *testing.B, next func(*DoubleBuffer[frame])) {
+		db := New(frame{}, frame{})
+		ctx := context.Background()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			back, _ := db.Back(ctx)
+			back[0] = byte(i)
+			db.Ready()
+			next(db)
+		}
+	}
+	b.Run("Next", func(b *testing.B) {
+		cycle(b, func(db *DoubleBuffer[frame]) { db.Next() })
+	})
+	b.Run("NextChanged", func(b *testing.B) {
+		cycle(b, func(db *DoubleBuffer[frame]) { db.NextChanged() })
+	})
+}
+
+func TestNilOptions(t *testing.T) {
+	db := New(0, 0, nil, WithBuffers(0),
+		WithClock[int](nil),
+		WithOnRecycle[int](nil),
+		WithOnBackCancel[int](nil),
+		WithReadyTap[int](nil),
+		WithFrontFilter[int](nil),
+		WithSequence[int](nil),
+		WithMergeOnOverwrite[int](nil),
+		WithSwapFunc[int](nil),
+		WithCopyOnFront[int](nil),
+		WithPanicHandler[int](nil),
+	)
+	for i := 1; i <= 2; i++ {
+		db.Publish(context.Background(), i)
+	}
+	if v, changed := db.Next(); !changed || v != 2 {
+		t.Fatalf("Next = %d, %t; want 2, true", v, changed)
+	}
+	if db.LastSwap().IsZero() {
+		t.Fatal("LastSwap is zero with WithClock(nil)")
+	}
+
+	db = New(0, 0, WithAdaptiveDepth[int](2, 3, nil))
+	db.Publish(context.Background(), 1)
+	if back, ok := db.TryBack(); !ok || *back != 0 {
+		t.Fatal("growing with a nil alloc did not hand out a zero buffer")
+	}
+}
@@ -0,0 +1,113 @@
+// Package doublebuftest provides utilities for testing code that consumes a
+// doublebuf.DoubleBuffer.
+package doublebuftest
+
+import (
+	"testing"
+
+	"github.com/jncornett/doublebuf"
+)
+
+// Poisoned is a DoubleBuffer that poisons every buffer it retires, i.e.
+// fills it with a sentinel right when a swap replaces it as the front, to
+// catch consumers that keep using a front value after a swap. It is meant
+// for a T that refers to shared storage, such as a slice, where a front
+// value held past a swap aliases the buffer the producer is about to
+// rewrite: with Poisoned, such a value reads the sentinel instead, so the
+// bug shows up deterministically and Check reports it.
+//
+// Front, FrontOK and Next report to the test if they return poisoned data,
+// which means the producer readied a recycled buffer without rewriting it.
+// All other methods are those of the embedded DoubleBuffer.
+type Poisoned[T any] struct {
+	*doublebuf.DoubleBuffer[T]
+	tb       testing.TB
+	poisoned func(T) bool
+}
+
+// NewPoisoned creates a Poisoned over a DoubleBuffer created by
+// doublebuf.New with a, b and opts. poison fills the retired buffer it is
+// passed with the sentinel in place, and poisoned reports whether a value
+// contains it; see PoisonSlice for slices.
+// Poisoning is done by a doublebuf.WithSwapFunc, so opts must not contain
+// another one, and the restrictions of WithSwapFunc apply: Front and Next
+// must be called from the same goroutine. NewPoisoned panics if poison or
+// poisoned is nil.
+func NewPoisoned[T any](tb testing.TB, a, b T, poison func(*T), poisoned func(T) bool, opts ...doublebuf.Option[T]) *Poisoned[T] {
+	if poison == nil || poisoned == nil {
+		panic("doublebuftest: NewPoisoned called with a nil poison function")
+	}
+	swap := doublebuf.WithSwapFunc(func(front, back *T) {
+		*front, *back = *back, *front
+		poison(back)
+	})
+	return &Poisoned[T]{
+		DoubleBuffer: doublebuf.New(a, b, append(opts[:len(opts):len(opts)], swap)...),
+		tb:           tb,
+		poisoned:     poisoned,
+	}
+}
+
+// Check reports a use-after-swap to the test if v, a front value the
+// consumer still holds, has been poisoned since it was read.
+func (p *Poisoned[T]) Check(v T) {
+	p.tb.Helper()
+	if p.poisoned(v) {
+		p.tb.Errorf("doublebuftest: front value %v used after it was retired by a swap", v)
+	}
+}
+
+// Front returns the front value, as DoubleBuffer.Front does, and reports to
+// the test if it is poisoned.
+func (p *Poisoned[T]) Front() T {
+	p.tb.Helper()
+	t := p.DoubleBuffer.Front()
+	p.checkFront(t)
+	return t
+}
+
+// FrontOK returns the front value, as DoubleBuffer.FrontOK does, and
+// reports to the test if it is poisoned.
+func (p *Poisoned[T]) FrontOK() (T, bool) {
+	p.tb.Helper()
+	t, ok := p.DoubleBuffer.FrontOK()
+	p.checkFront(t)
+	return t, ok
+}
+
+// Next swaps as DoubleBuffer.Next does, and reports to the test if the new
+// front value is poisoned.
+func (p *Poisoned[T]) Next() (T, bool) {
+	p.tb.Helper()
+	t, changed := p.DoubleBuffer.Next()
+	p.checkFront(t)
+	return t, changed
+}
+
+func (p *Poisoned[T]) checkFront(t T) {
+	p.tb.Helper()
+	if p.poisoned(t) {
+		p.tb.Errorf("doublebuftest: front value %v is poisoned, the producer readied a recycled buffer without rewriting it", t)
+	}
+}
+
+// PoisonSlice returns poison and poisoned functions for NewPoisoned with a
+// slice T, using sentinel as the poison: poison overwrites every element of
+// the retired slice with sentinel, and poisoned reports whether any element
+// equals it. sentinel must be a value that the producer never writes.
+func PoisonSlice[E comparable](sentinel E) (poison func(*[]E), poisoned func([]E) bool) {
+	poison = func(s *[]E) {
+		for i := range *s {
+			(*s)[i] = sentinel
+		}
+	}
+	poisoned = func(s []E) bool {
+		for _, e := range s {
+			if e == sentinel {
+				return true
+			}
+		}
+		return false
+	}
+	return poison, poisoned
+}
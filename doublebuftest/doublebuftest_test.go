@@ -0,0 +1,52 @@
+package doublebuftest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// recorder is a testing.TB that records errors instead of failing.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestPoisoned(t *testing.T) {
+	rec := &recorder{TB: t}
+	poison, poisoned := PoisonSlice(-1)
+	db := NewPoisoned(rec, []int{0}, []int{0}, poison, poisoned)
+
+	held := db.Front()
+	back, _ := db.Back(context.Background())
+	(*back)[0] = 1
+	db.Ready()
+	if v, changed := db.Next(); !changed || v[0] != 1 {
+		t.Fatalf("Next = %v, %t; want [1], true", v, changed)
+	}
+	if len(rec.errs) != 0 {
+		t.Fatalf("errors before the stale value was used: %v", rec.errs)
+	}
+	db.Check(db.Front())
+	if len(rec.errs) != 0 {
+		t.Fatalf("current front reported as stale: %v", rec.errs)
+	}
+	db.Check(held)
+	if len(rec.errs) != 1 {
+		t.Fatalf("use after swap not reported, errors = %v", rec.errs)
+	}
+
+	// A producer that readies the recycled buffer as it is leaks the poison.
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	if len(rec.errs) != 2 {
+		t.Fatalf("poisoned front not reported, errors = %v", rec.errs)
+	}
+}
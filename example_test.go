@@ -0,0 +1,26 @@
+package doublebuf_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jncornett/doublebuf"
+)
+
+type Event any
+
+type KeyPress struct{ Key rune }
+
+type Resize struct{ W, H int }
+
+func ExampleOnFront() {
+	db := doublebuf.New[Event](nil, nil)
+	back, _ := db.Back(context.Background())
+	*back = Resize{W: 80, H: 24}
+	db.Ready()
+	db.Next()
+
+	doublebuf.OnFront(db, func(e KeyPress) { fmt.Println("key", string(e.Key)) })
+	doublebuf.OnFront(db, func(e Resize) { fmt.Println("resize", e.W, e.H) })
+	// Output: resize 80 24
+}
@@ -0,0 +1,20 @@
+package doublebuf
+
+import "expvar"
+
+// WithExpvar publishes the statistics of the DoubleBuffer as an expvar.Map
+// under name, so they show up on the /debug/vars endpoint of the standard
+// library without a metrics dependency. The map holds "swaps", "dropped"
+// and "depth", as reported by SwapCount, Dropped and Depth. Its values are
+// read when the map is rendered, so the hot path does nothing extra.
+// Like expvar.Publish, WithExpvar panics if name is already in use, and the
+// published map keeps the DoubleBuffer reachable for the lifetime of the
+// program, so WithFinalizer never fires for it.
+func WithExpvar[T any](name string) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		m := expvar.NewMap(name)
+		m.Set("swaps", expvar.Func(func() any { return db.SwapCount() }))
+		m.Set("dropped", expvar.Func(func() any { return db.Dropped() }))
+		m.Set("depth", expvar.Func(func() any { return db.Depth() }))
+	}
+}
@@ -0,0 +1,56 @@
+package doublebuf
+
+// WithFrontFilter makes Next check each readied frame with keep before
+// swapping it in. A frame for which keep returns false never becomes the
+// front: its buffer is recycled to the free list, the swap is suppressed and
+// Next returns the current front with changed set to false, just as if
+// nothing had been readied, so a filtered frame is indistinguishable from no
+// frame for the consumer. Filtered counts such frames; they count neither as
+// swaps nor as idle calls. Ready calls coalesced into a filtered frame are
+// discarded with it, so they are not reported by the next swap.
+// keep runs on the consumer's goroutine, under the lock that serializes
+// swaps, and must not retain the pointer. If keep panics, the frame is
+// treated as filtered, see WithPanicHandler. A nil keep keeps every frame.
+func WithFrontFilter[T any](keep func(*T) bool) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.keep = keep }
+}
+
+// filter reports whether the frame in next passes WithSequence and
+// WithFrontFilter, and recycles next otherwise. db.swapMu must be held and
+// next must have been taken from db.next.
+func (db *DoubleBuffer[T]) filter(next *slot[T]) (kept bool) {
+	counter := &db.filtered
+	defer func() {
+		if !kept { // also on a panic in a callback, before unwinding
+			db.readies.Store(0)
+			counter.Add(1)
+			db.prev <- next
+			db.swapped.notify()
+		}
+	}()
+	if db.seqOf != nil {
+		front := db.front.Load()
+		counter = &db.outOfOrder
+		db.invoke("Sequence", func() { kept = db.seqOf(next.p) > db.seqOf(front.p) })
+		if !kept {
+			return false
+		}
+		counter = &db.filtered
+	}
+	if db.keep != nil {
+		db.invoke("FrontFilter", func() { kept = db.keep(next.p) })
+	}
+	return kept
+}
+
+// WithSequence makes Next only promote frames that are newer than the
+// front, according to a sequence number that seq reads from a frame, e.g. a
+// field of T. A readied frame whose sequence number is not greater than the
+// front's, i.e. a duplicate or out-of-order frame, is recycled as by
+// WithFrontFilter, and counted by OutOfOrder instead of Filtered. The
+// sequence check runs before the predicate of WithFrontFilter, if both are
+// given. seq runs under the lock that serializes swaps and must not retain
+// the pointer. A nil seq disables the check.
+func WithSequence[T any](seq func(*T) uint64) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.seqOf = seq }
+}
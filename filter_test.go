@@ -0,0 +1,47 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithFrontFilter(t *testing.T) {
+	db := New(0, 0, WithFrontFilter(func(p *int) bool { return *p%2 == 0 }))
+	for i := 1; i <= 4; i++ {
+		db.Publish(context.Background(), i)
+		v, changed := db.Next()
+		if want := i%2 == 0; changed != want {
+			t.Fatalf("Next for frame %d: changed = %t, want %t", i, changed, want)
+		}
+		if want := i - i%2; v != want {
+			t.Fatalf("Next for frame %d = %d, want %d", i, v, want)
+		}
+	}
+	if got := db.Filtered(); got != 2 {
+		t.Fatalf("Filtered = %d, want 2", got)
+	}
+	if got := db.SwapCount(); got != 2 {
+		t.Fatalf("SwapCount = %d, want 2", got)
+	}
+}
+
+func TestWithSequence(t *testing.T) {
+	type frame struct{ seq, v int }
+	db := New(frame{}, frame{}, WithSequence(func(p *frame) uint64 { return uint64(p.seq) }))
+	for _, f := range []frame{{1, 1}, {3, 3}, {2, 2}, {3, 4}, {4, 5}} {
+		db.Publish(context.Background(), f)
+		db.Next()
+	}
+	if got := db.Front(); got.v != 5 {
+		t.Fatalf("Front = %+v, want frame 5", got)
+	}
+	if got := db.OutOfOrder(); got != 2 {
+		t.Fatalf("OutOfOrder = %d, want 2", got)
+	}
+	if got := db.SwapCount(); got != 3 {
+		t.Fatalf("SwapCount = %d, want 3", got)
+	}
+	if got := db.Filtered(); got != 0 {
+		t.Fatalf("Filtered = %d, want 0", got)
+	}
+}
@@ -0,0 +1,52 @@
+package doublebuf
+
+import "log"
+
+// WithFinalizer installs a safety net for DoubleBuffers whose owner may
+// forget to close them, e.g. buffers holding OS resources in a long-running
+// service: if the DoubleBuffer is garbage collected without having been
+// closed, a finalizer logs a warning with the standard logger, closes it as
+// by Close and then calls cleanup, which can release whatever the frames
+// hold. A nil cleanup only closes. There is nothing left to drain at that
+// point, since no consumer can reach the DoubleBuffer any more.
+//
+// Explicit Close is still preferred; the finalizer is best effort and
+// inherits the caveats of runtime.SetFinalizer. It runs on a single
+// goroutine shared by all finalizers of the program, at an unspecified time
+// after the DoubleBuffer becomes unreachable, or not at all, e.g. if the
+// program exits first or the DoubleBuffer is reachable from a value that
+// refers back to it, such as a frame or a callback holding it. A slow
+// cleanup delays every other finalizer. cleanup must not keep the
+// DoubleBuffer reachable.
+//
+// To keep the DoubleBuffer itself out of reference cycles, New moves the
+// initial buffers out of the DoubleBuffer into allocations of their own, as
+// WithCacheAligned does.
+func WithFinalizer[T any](cleanup func()) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		db.finalizer = true
+		db.cleanup = cleanup
+	}
+}
+
+// finalize is the finalizer installed by WithFinalizer.
+func (db *DoubleBuffer[T]) finalize() {
+	if db.closed.Load() {
+		return
+	}
+	log.Printf("doublebuf: DoubleBuffer[%T] garbage collected without Close", *new(T))
+	db.Close()
+	if db.cleanup != nil {
+		db.cleanup()
+	}
+}
+
+// detach moves the inline buffers a and b of db to allocations of their
+// own, so db is not referenced from its slots.
+func (db *DoubleBuffer[T]) detach() (a, b *T) {
+	a, b = new(T), new(T)
+	*a, *b = db.a, db.b
+	var zero T
+	db.a, db.b = zero, zero
+	return a, b
+}
@@ -0,0 +1,42 @@
+package doublebuf
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWithFinalizer(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	collect := func(closeFirst bool) bool {
+		cleaned := make(chan struct{}, 1)
+		func() {
+			db := New(0, 0, WithFinalizer[int](func() { cleaned <- struct{}{} }))
+			db.Publish(context.Background(), 1)
+			if closeFirst {
+				db.Close()
+			}
+		}()
+		for i := 0; i < 10; i++ {
+			runtime.GC()
+			select {
+			case <-cleaned:
+				return true
+			case <-time.After(10 * time.Millisecond):
+			}
+		}
+		return false
+	}
+	if !collect(false) {
+		t.Fatal("cleanup did not run for a DoubleBuffer collected without Close")
+	}
+	if collect(true) {
+		t.Fatal("cleanup ran for a DoubleBuffer that was closed")
+	}
+}
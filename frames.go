@@ -0,0 +1,217 @@
+package doublebuf
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Frames returns an iterator over the front values of successive swaps.
+// Each iteration blocks until a frame is readied, swaps it in like Next and
+// yields the new front. Iteration stops when ctx is done, when the
+// DoubleBuffer is closed and no readied frame is left, or when the loop body
+// breaks; no goroutine is left behind in any case.
+//
+//	for v := range db.Frames(ctx) {
+//		render(v)
+//	}
+func (db *DoubleBuffer[T]) Frames(ctx context.Context) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			t, err := db.nextWait(ctx)
+			if err != nil || !yield(t) {
+				return
+			}
+		}
+	}
+}
+
+// AsChannel consumes the DoubleBuffer on a new goroutine, as Frames does,
+// and sends each new front value on the returned channel, which has a
+// buffer of size buf, for interop with channel-based pipelines. When the
+// channel is full the goroutine blocks, and stops swapping meanwhile, so the
+// DoubleBuffer keeps only the newest readied frame, as it does for any slow
+// consumer: frames readied while the receiver lags behind are dropped, not
+// queued. The goroutine stops and closes the channel when ctx is done, or
+// once the DoubleBuffer is closed and no readied frame is left; a value it
+// is blocked sending when ctx is done or Close is called is discarded, so a
+// receiver that stopped reading does not keep it alive. After CloseDrain
+// the goroutine keeps trying to deliver the final frames, so the receiver
+// must drain the channel until it is closed.
+// The goroutine is the consumer of the DoubleBuffer, so nothing else may
+// call Next while it runs.
+func (db *DoubleBuffer[T]) AsChannel(ctx context.Context, buf int) <-chan T {
+	c := make(chan T, buf)
+	go func() {
+		defer close(c)
+		for t := range db.Frames(ctx) {
+			select {
+			case c <- t:
+			case <-ctx.Done():
+				return
+			case <-db.aborted:
+				return
+			}
+		}
+	}()
+	return c
+}
+
+// RunConsumerTicked consumes the DoubleBuffer at a fixed rate, for example
+// a display refreshing at 60Hz independently of how fast the producer
+// readies frames: every interval, it swaps in the readied frame if there is
+// one, as NextErr does, and calls onFrame with the front value, new or held
+// from the previous tick. If several frames were readied within one
+// interval, the latest wins: with extra buffers, see WithBuffers, the
+// others were dropped and counted by Dropped, while with the two buffers of
+// New the producer waits in Back for the next tick instead.
+// RunConsumerTicked runs until ctx is done, returning ctx.Err(), or until
+// NextErr returns an error, which it returns, e.g. ErrClosed once the
+// DoubleBuffer is closed and the final frame has been passed to onFrame.
+// It is the consumer of the DoubleBuffer while it runs, and calls onFrame
+// on the calling goroutine.
+func (db *DoubleBuffer[T]) RunConsumerTicked(ctx context.Context, interval time.Duration, onFrame func(T)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+		t, changed, err := db.NextErr()
+		if changed || err == nil {
+			onFrame(t)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ConsumeDeadline consumes the DoubleBuffer with a time budget per frame,
+// for soft real-time display or control loops that must stay responsive:
+// it waits for each readied frame as Frames does and calls onFrame with it
+// and a context derived from ctx that expires after budget. onFrame should
+// abandon the frame once its context is done; it cannot be interrupted
+// otherwise. The next iteration then swaps in the newest frame readied in
+// the meantime, skipping the older ones, so a slow frame delays at most the
+// one after it.
+// Skipped frames are counted as for any slow consumer: with extra buffers,
+// see WithBuffers, a frame replaced before being swapped in counts in
+// Dropped, while with the two buffers of New the producer waits in Back
+// instead. An abandoned frame was swapped in, so it counts in SwapCount, not
+// in Dropped.
+// ConsumeDeadline runs until ctx is done, returning ctx.Err(), or until the
+// DoubleBuffer is closed and no readied frame is left, returning ErrClosed
+// or the error recorded by CloseWithError. It is the consumer of the
+// DoubleBuffer while it runs, and calls onFrame on the calling goroutine.
+func (db *DoubleBuffer[T]) ConsumeDeadline(ctx context.Context, budget time.Duration, onFrame func(ctx context.Context, t T)) error {
+	for {
+		t, err := db.nextWait(ctx)
+		if err != nil {
+			return err
+		}
+		frameCtx, cancel := context.WithTimeout(ctx, budget)
+		onFrame(frameCtx, t)
+		cancel()
+	}
+}
+
+// FromChannel creates a DoubleBuffer as New does and feeds it from in on a
+// new goroutine, which acts as its producer: each value received from in is
+// copied into the back buffer and readied, as by Publish, so the consumer
+// sees the latest value while the channel sender never waits for it. To
+// that end the DoubleBuffer gets a spare zero buffer on top of opts, see
+// WithBuffers, so a value that arrives while the previous one is still
+// pending replaces it and is counted by Dropped instead of blocking the
+// goroutine; options that make Ready wait, such as WithBackpressure, bring
+// the wait back. For pointer, slice or map types only the reference is
+// copied, so the value the consumer sees shares its data with the sender.
+// Once in is closed, the goroutine closes the DoubleBuffer, leaving the last
+// value, if not swapped in yet, available to Next; NextErr then reports
+// ErrClosed after it. When ctx is done, the goroutine closes the
+// DoubleBuffer as by Close and discards any value it has not published.
+// The goroutine is the producer of the DoubleBuffer, so nothing else may
+// call Back or Ready.
+func FromChannel[T any](ctx context.Context, in <-chan T, a, b T, opts ...Option[T]) *DoubleBuffer[T] {
+	var spare T
+	db := New(a, b, append([]Option[T]{WithBuffers(spare)}, opts...)...)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				db.Close()
+				return
+			case v, ok := <-in:
+				if !ok {
+					db.shutdown(nil) // as CloseDrain, without waiting for the consumer
+					return
+				}
+				if err := db.Publish(ctx, v); err != nil {
+					db.Close()
+					return
+				}
+			}
+		}
+	}()
+	return db
+}
+
+// FrontNewerThan returns the front value right away if the last swap
+// happened at most maxAge ago, as measured with the clock of WithClock.
+// Otherwise the front is considered stale, and FrontNewerThan swaps in the
+// next readied frame like Next, waiting for one if none is pending yet. It
+// returns ctx.Err() if ctx is done first, or ErrClosed if the DoubleBuffer is
+// closed and no readied frame is left.
+// Before the first swap, the age of the front is the age of the DoubleBuffer.
+func (db *DoubleBuffer[T]) FrontNewerThan(ctx context.Context, maxAge time.Duration) (T, error) {
+	if db.now().Sub(db.LastSwap()) <= maxAge {
+		return db.Front(), nil
+	}
+	return db.nextWait(ctx)
+}
+
+// nextWait swaps in the next readied frame, waiting for one if necessary.
+// It returns ctx.Err() if ctx is done first, or ErrClosed if the
+// DoubleBuffer is closed and no readied frame is left.
+func (db *DoubleBuffer[T]) nextWait(ctx context.Context) (t T, err error) {
+	for {
+		if t, changed := db.Next(); changed {
+			return t, nil
+		}
+		// Grab the wait channel before checking again, so a Ready in between is not missed.
+		readied := db.readied.wait()
+		if t, changed := db.Next(); changed {
+			db.readied.release(readied)
+			return t, nil
+		}
+		if db.closed.Load() && !db.pending() {
+			db.readied.release(readied)
+			return t, db.errClosed()
+		}
+		done := db.done
+		var blackout <-chan time.Time
+		var timer *time.Timer
+		if left := db.blackoutLeft(); left > 0 {
+			timer = time.NewTimer(left)
+			blackout = timer.C
+			if db.closed.Load() {
+				done = nil // only the end of the blackout can make progress
+			}
+		}
+		select {
+		case <-ctx.Done():
+			db.readied.release(readied)
+			return t, ctx.Err()
+		case <-done:
+			db.readied.release(readied)
+		case <-readied:
+		case <-blackout:
+			db.readied.release(readied)
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
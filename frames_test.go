@@ -0,0 +1,217 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFrames(t *testing.T) {
+	db := New(0, 0)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			back, err := db.Back(context.Background())
+			if err != nil {
+				return
+			}
+			*back = i
+			db.Ready()
+		}
+	}()
+	want := 1
+	for v := range db.Frames(context.Background()) {
+		if v != want {
+			t.Fatalf("frame = %d, want %d", v, want)
+		}
+		if want == 3 {
+			break
+		}
+		want++
+	}
+}
+
+func TestFramesCancel(t *testing.T) {
+	db := New(0, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for range db.Frames(ctx) {
+		t.Fatal("Frames yielded after cancel")
+	}
+}
+
+func TestFramesClosed(t *testing.T) {
+	db := New(0, 0)
+	back, _ := db.Back(context.Background())
+	*back = 1
+	db.Ready()
+	db.Close()
+	for range db.Frames(context.Background()) {
+		t.Fatal("Frames yielded after Close")
+	}
+}
+
+func TestFrontNewerThan(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now))
+	if v, err := db.FrontNewerThan(context.Background(), time.Second); err != nil || v != 0 {
+		t.Fatalf("FrontNewerThan on a fresh front = %d, %v; want 0, nil", v, err)
+	}
+	clock.advance(2 * time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := db.FrontNewerThan(ctx, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("FrontNewerThan on a stale front: got %v, want DeadlineExceeded", err)
+	}
+	db.Publish(context.Background(), 1)
+	if v, err := db.FrontNewerThan(context.Background(), time.Second); err != nil || v != 1 {
+		t.Fatalf("FrontNewerThan with a pending frame = %d, %v; want 1, nil", v, err)
+	}
+}
+
+func TestAsChannel(t *testing.T) {
+	db := New(0, 0)
+	c := db.AsChannel(context.Background(), 0)
+	for i := 1; i <= 3; i++ {
+		db.Publish(context.Background(), i)
+		if v := <-c; v != i {
+			t.Fatalf("received %d, want %d", v, i)
+		}
+	}
+	db.Close()
+	if _, ok := <-c; ok {
+		t.Fatal("channel not closed after Close")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c = New(0, 0).AsChannel(ctx, 1)
+	cancel()
+	if _, ok := <-c; ok {
+		t.Fatal("channel not closed after ctx was cancelled")
+	}
+
+	// A receiver that stopped reading does not keep the goroutine alive.
+	db = New(0, 0)
+	c = db.AsChannel(context.Background(), 0)
+	db.Publish(context.Background(), 1)
+	for db.pending() { // the goroutine swapped it in and blocks sending it
+		time.Sleep(time.Millisecond)
+	}
+	db.Close()
+	time.Sleep(10 * time.Millisecond)
+	if v, ok := <-c; ok {
+		t.Fatalf("received %d after Close, want the channel closed", v)
+	}
+}
+
+func TestFromChannel(t *testing.T) {
+	in := make(chan int)
+	db := FromChannel(context.Background(), in, 0, 0)
+	for i := 1; i <= 3; i++ {
+		in <- i
+		if v, err := db.nextWait(context.Background()); err != nil || v != i {
+			t.Fatalf("nextWait = %d, %v; want %d, nil", v, err, i)
+		}
+	}
+	in <- 4
+	close(in)
+	var got []int
+	for v := range db.Frames(context.Background()) {
+		got = append(got, v)
+	}
+	if len(got) != 1 || got[0] != 4 {
+		t.Fatalf("Frames after the channel closed yielded %v, want [4]", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	db = FromChannel(ctx, make(chan int), 0, 0)
+	cancel()
+	if _, err := db.nextWait(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("nextWait after ctx was cancelled = %v, want ErrClosed", err)
+	}
+}
+
+func TestFromChannelNoConsumer(t *testing.T) {
+	in := make(chan int)
+	db := FromChannel(context.Background(), in, 0, 0)
+	defer db.Close()
+	for i := 1; i <= 10; i++ {
+		select {
+		case in <- i:
+		case <-time.After(time.Second):
+			t.Fatalf("send %d blocked without a consumer", i)
+		}
+	}
+	close(in)
+	for !db.closed.Load() { // the goroutine has published everything
+		time.Sleep(time.Millisecond)
+	}
+	var got []int
+	for v := range db.Frames(context.Background()) {
+		got = append(got, v)
+	}
+	if len(got) != 1 || got[0] != 10 {
+		t.Fatalf("Frames yielded %v, want [10]", got)
+	}
+	if d := db.Dropped(); d != 9 {
+		t.Fatalf("Dropped = %d, want 9", d)
+	}
+}
+
+func TestRunConsumerTicked(t *testing.T) {
+	db := New(0, 0, WithBuffers(0))
+	db.Publish(context.Background(), 1)
+	db.Publish(context.Background(), 2)
+	var got []int
+	err := db.RunConsumerTicked(context.Background(), time.Millisecond, func(v int) {
+		got = append(got, v)
+		if len(got) == 2 {
+			db.Close()
+		}
+	})
+	if !errors.Is(err, ErrClosed) {
+		t.Fatalf("RunConsumerTicked = %v, want ErrClosed", err)
+	}
+	if len(got) != 2 || got[0] != 2 || got[1] != 2 {
+		t.Fatalf("onFrame got %v, want [2 2]", got)
+	}
+	if d := db.Dropped(); d != 1 {
+		t.Fatalf("Dropped = %d, want 1", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	if err := New(0, 0).RunConsumerTicked(ctx, time.Millisecond, func(int) {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("RunConsumerTicked = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestConsumeDeadline(t *testing.T) {
+	db := New(0, 0, WithBuffers(1))
+	db.Publish(context.Background(), 1)
+	var got []int
+	err := db.ConsumeDeadline(context.Background(), 10*time.Millisecond, func(ctx context.Context, v int) {
+		got = append(got, v)
+		switch v {
+		case 1: // overrun the budget while newer frames arrive
+			for i := 2; i <= 4; i++ {
+				db.Publish(context.Background(), i)
+			}
+			<-ctx.Done()
+			if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				t.Errorf("frame context = %v, want DeadlineExceeded", ctx.Err())
+			}
+		case 4:
+			db.Close()
+		}
+	})
+	if !errors.Is(err, ErrClosed) {
+		t.Fatalf("ConsumeDeadline = %v, want ErrClosed", err)
+	}
+	if len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Fatalf("onFrame got %v, want [1 4]", got)
+	}
+	if d := db.Dropped(); d != 2 {
+		t.Fatalf("Dropped = %d, want 2", d)
+	}
+}
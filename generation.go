@@ -0,0 +1,211 @@
+package doublebuf
+
+import (
+	"context"
+	"runtime"
+)
+
+// Generation returns the number of times the front has changed, either by a
+// swap or by a successful CompareAndSwapFront. It starts at 0.
+// Generation is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) Generation() uint64 { return db.gen.Load() }
+
+// FrontIfChanged returns the front value if the generation differs from
+// token, the generation a previous call returned, so that a polling consumer
+// only processes a front it has not seen yet. It returns the current
+// generation as the token to pass next time, and changed set to whether the
+// front was returned; if not, t is the zero value and nothing is copied.
+// Pass 0 on the first call to get the initial front once something has
+// replaced it, or any value other than the current generation to get it
+// unconditionally. The front and the generation are read as one snapshot,
+// as by FrontAndReady.
+// FrontIfChanged is safe to call concurrently with Next.
+func (db *DoubleBuffer[T]) FrontIfChanged(token uint64) (t T, gen uint64, changed bool) {
+	for {
+		gen = db.gen.Load()
+		if gen == token {
+			return t, gen, false
+		}
+		t = *db.loadFront().p
+		if db.gen.Load() == gen {
+			return db.copyOut(t), gen, true
+		}
+	}
+}
+
+// FrontIdentity reports which physical buffer is the front, for callers
+// that keep state per buffer: slot is 0 for the buffer passed to the
+// constructor as a, 1 for b, and 2 onwards for the extra buffers of
+// WithBuffers, in order, and for buffers allocated by WithAdaptiveDepth,
+// whose ids are never reused, and -1 on a DoubleBuffer that was not created
+// by one of the constructors. gen is the current generation, as by
+// Generation, which tells apart the successive times the same buffer is the
+// front. Both are read as one snapshot.
+// FrontIdentity is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) FrontIdentity() (slot int, gen uint64) {
+	for {
+		gen = db.gen.Load()
+		slot = db.loadFront().id
+		if db.gen.Load() == gen {
+			return slot, gen
+		}
+	}
+}
+
+// FrontIsA reports whether the front is the buffer passed to the
+// constructor as a, the initial back buffer, e.g. for a test asserting that
+// swaps alternate between the two buffers. It is a shorthand for a slot of
+// 0 in FrontIdentity, so it remains true for that buffer when SwapBuffers
+// has replaced its storage.
+// FrontIsA is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) FrontIsA() bool { return db.loadFront().id == 0 }
+
+// Observe returns the front value, its generation and whether a readied
+// frame is pending, in one call, for consumers making scheduling decisions.
+// value and gen are a consistent snapshot, as by FrontSeqlock: value is the
+// front of generation gen, even if swaps or writers such as TakeFront run
+// concurrently; Observe retries instead of returning a mix. pending is
+// sampled while that front is current, but a producer can ready a frame,
+// and a consumer swap it in, at any time after Observe returns, so it is
+// only a hint. WithCopyOnFront applies to value.
+// Observe is safe to call concurrently with all other methods, with the
+// caveats of FrontSeqlock for the race detector.
+func (db *DoubleBuffer[T]) Observe() (value T, gen uint64, pending bool) {
+	for {
+		seq := db.seq.Load()
+		if seq&1 != 0 { // a swap is in progress
+			runtime.Gosched()
+			continue
+		}
+		gen = db.gen.Load()
+		value = *db.loadFront().p
+		pending = db.pending()
+		if db.seq.Load() == seq {
+			return db.copyOut(value), gen, pending
+		}
+	}
+}
+
+// CompareAndSwapFront replaces the front value with new if it currently
+// equals old, and reports whether it did.
+// The front buffer is overwritten in place, atomically with respect to Next
+// and other front writers, but not to concurrent readers of Front.
+//
+// Comparing values alone is subject to the ABA problem: between reading the
+// front and calling CompareAndSwapFront, the front may have changed from old
+// to something else and back to old, and the swap still succeeds. Use
+// CompareAndSwapFrontGen when that matters.
+func CompareAndSwapFront[T comparable](db *DoubleBuffer[T], old, new T) bool {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	return casFront(db, old, new)
+}
+
+// CompareAndSwapFrontGen is like CompareAndSwapFront, but only succeeds if
+// the generation is also still oldGen, so it fails if the front changed in
+// between, even back to an equal value. A successful swap bumps the
+// generation.
+func CompareAndSwapFrontGen[T comparable](db *DoubleBuffer[T], oldVal T, oldGen uint64, new T) bool {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	if db.gen.Load() != oldGen {
+		return false
+	}
+	return casFront(db, oldVal, new)
+}
+
+// WaitFor blocks until the front value equals want, for state-machine-style
+// coordination with the consumer, and returns nil; it returns right away if
+// the front already equals want. WaitFor does not swap: it re-checks the
+// front each time the consumer swaps, so it only observes the values that
+// become the front, not intermediate writes of the producer, nor frames
+// that were dropped or skipped without being swapped in. The front is read
+// as by FrontSeqlock.
+// WaitFor returns ctx.Err() if ctx is done first, and the error of NextErr
+// if the DoubleBuffer is closed and no readied frame is left to swap in.
+// WaitFor is safe to call concurrently with all other methods.
+func WaitFor[T comparable](ctx context.Context, db *DoubleBuffer[T], want T) error {
+	for {
+		// Grab the wait channel before checking, so a swap in between is not missed.
+		swapped := db.swapped.wait()
+		if db.frontSeqlock() == want {
+			return nil
+		}
+		if db.closed.Load() && !db.pending() {
+			return db.errClosed()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-swapped:
+		}
+	}
+}
+
+// EqualFront reports whether the front values of a and b are equal
+// according to ==, e.g. to assert in tests that two pipelines produced the
+// same output. Each front is read as by FrontSeqlock, so neither is torn by
+// a concurrent swap, but the two are read one after the other, not as one
+// snapshot. EqualFront is safe to call concurrently with all other methods,
+// and a DoubleBuffer always equals itself.
+func EqualFront[T comparable](a, b *DoubleBuffer[T]) bool {
+	if a == b {
+		return true
+	}
+	return a.frontSeqlock() == b.frontSeqlock()
+}
+
+// TakeFront returns the front value and replaces it with replacement in one
+// step, for consumers that read and then reset the displayed state, such as
+// draining an accumulated value. It operates on the value in the front
+// buffer, like CompareAndSwapFront, not on the swap machinery: no buffer
+// changes hands and nothing pending is consumed, but the generation is
+// bumped. The front buffer is overwritten in place, atomically with respect
+// to Next and other front writers, but not to concurrent readers of Front.
+func (db *DoubleBuffer[T]) TakeFront(replacement T) T {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	t := *db.front.Load().p
+	db.overwriteFront(replacement)
+	return t
+}
+
+// ResetFront overwrites the front value with v, e.g. a blank frame to clear
+// the display on a logical reset while the producer keeps running. It only
+// touches the front: the back buffer, a pending frame and the retired
+// buffers are left alone, so the next swap shows the producer's frame as
+// usual. The generation is bumped. Like TakeFront, ResetFront is atomic
+// with respect to Next and other front writers, and readers using
+// FrontSeqlock, Observe or FrontChecked never see a mix of the old and new
+// value; plain Front readers must not run concurrently with it.
+func (db *DoubleBuffer[T]) ResetFront(v T) {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	db.overwriteFront(v)
+}
+
+// overwriteFront stores v in the front buffer inside a seqlock write
+// section and bumps the generation; db.swapMu must be held.
+func (db *DoubleBuffer[T]) overwriteFront(v T) {
+	s := db.front.Load()
+	db.seq.Add(1)
+	s.sum.Store(0) // see WithChecksum
+	*s.p = v
+	db.gen.Add(1)
+	db.seq.Add(1)
+}
+
+// casFront implements the front compare-and-swap; db.swapMu must be held.
+func casFront[T comparable](db *DoubleBuffer[T], old, new T) bool {
+	s := db.front.Load()
+	front := s.p
+	if *front != old {
+		return false
+	}
+	db.seq.Add(1)
+	s.sum.Store(0) // see WithChecksum
+	*front = new
+	db.gen.Add(1)
+	db.seq.Add(1)
+	return true
+}
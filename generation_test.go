@@ -0,0 +1,172 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGeneration(t *testing.T) {
+	db := New(0, 0)
+	if g := db.Generation(); g != 0 {
+		t.Fatalf("Generation = %d, want 0", g)
+	}
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	db.Next()
+	if g := db.Generation(); g != 1 {
+		t.Fatalf("Generation = %d, want 1", g)
+	}
+}
+
+func TestCompareAndSwapFront(t *testing.T) {
+	db := New(0, 1)
+	if CompareAndSwapFront(db, 0, 2) {
+		t.Fatal("CompareAndSwapFront succeeded with a stale value")
+	}
+	if !CompareAndSwapFront(db, 1, 2) || db.Front() != 2 {
+		t.Fatal("CompareAndSwapFront failed with the current value")
+	}
+}
+
+func TestCompareAndSwapFrontGenABA(t *testing.T) {
+	db := New(0, 1)
+	gen := db.Generation()
+	// The front goes 1 -> 2 -> 1 behind our back.
+	CompareAndSwapFront(db, 1, 2)
+	CompareAndSwapFront(db, 2, 1)
+	if CompareAndSwapFrontGen(db, 1, gen, 3) {
+		t.Fatal("CompareAndSwapFrontGen succeeded across an ABA change")
+	}
+	if !CompareAndSwapFrontGen(db, 1, db.Generation(), 3) || db.Front() != 3 {
+		t.Fatal("CompareAndSwapFrontGen failed with the current value and generation")
+	}
+}
+
+func TestFrontIfChanged(t *testing.T) {
+	db := New(0, 0)
+	if _, token, changed := db.FrontIfChanged(0); changed || token != 0 {
+		t.Fatalf("FrontIfChanged before a swap = %d, %t; want 0, false", token, changed)
+	}
+	db.Publish(context.Background(), 1)
+	db.Next()
+	v, token, changed := db.FrontIfChanged(0)
+	if !changed || v != 1 || token != 1 {
+		t.Fatalf("FrontIfChanged after a swap = %d, %d, %t; want 1, 1, true", v, token, changed)
+	}
+	if _, token, changed = db.FrontIfChanged(token); changed || token != 1 {
+		t.Fatalf("FrontIfChanged with a current token = %d, %t; want 1, false", token, changed)
+	}
+}
+
+func TestFrontIdentity(t *testing.T) {
+	db := New(0, 0)
+	if slot, gen := db.FrontIdentity(); slot != 1 || gen != 0 {
+		t.Fatalf("FrontIdentity = %d, %d; want 1, 0", slot, gen)
+	}
+	for i, want := range []int{0, 1, 0} {
+		db.Publish(context.Background(), i)
+		db.Next()
+		if slot, gen := db.FrontIdentity(); slot != want || gen != uint64(i+1) {
+			t.Fatalf("FrontIdentity after swap %d = %d, %d; want %d, %d", i+1, slot, gen, want, i+1)
+		}
+	}
+}
+
+func TestTakeFront(t *testing.T) {
+	db := New(0, 5)
+	if v := db.TakeFront(0); v != 5 {
+		t.Fatalf("TakeFront = %d, want 5", v)
+	}
+	if v := db.Front(); v != 0 {
+		t.Fatalf("Front after TakeFront = %d, want 0", v)
+	}
+	if g := db.Generation(); g != 1 {
+		t.Fatalf("Generation after TakeFront = %d, want 1", g)
+	}
+}
+
+func TestResetFront(t *testing.T) {
+	db := New(0, 0, WithBuffers(1))
+	db.Publish(context.Background(), 1)
+	db.Next()
+	db.Publish(context.Background(), 2) // pending
+	back, _ := db.Back(context.Background())
+	*back = 3 // in flight
+	db.ResetFront(-1)
+	if v := db.FrontSeqlock(); v != -1 {
+		t.Fatalf("Front after ResetFront = %d, want -1", v)
+	}
+	if g := db.Generation(); g != 2 {
+		t.Fatalf("Generation after ResetFront = %d, want 2", g)
+	}
+	if v, changed := db.Next(); !changed || v != 2 {
+		t.Fatalf("Next after ResetFront = %d, %t; want the pending 2, true", v, changed)
+	}
+	db.Ready()
+	if v, changed := db.Next(); !changed || v != 3 {
+		t.Fatalf("Next = %d, %t; want the in-flight 3, true", v, changed)
+	}
+}
+
+func TestEqualFront(t *testing.T) {
+	a, b := New(0, 1), New(0, 2)
+	if EqualFront(a, b) {
+		t.Fatal("EqualFront of fronts 1 and 2 = true")
+	}
+	b.ResetFront(1)
+	if !EqualFront(a, b) || !EqualFront(a, a) {
+		t.Fatal("EqualFront of equal fronts = false")
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	db := New(0, 0)
+	db.Publish(context.Background(), 1)
+	db.Next()
+	errc := make(chan error, 1)
+	go func() { errc <- WaitFor(context.Background(), db, 2) }()
+	db.Publish(context.Background(), 2)
+	db.Next()
+	if err := <-errc; err != nil {
+		t.Fatalf("WaitFor = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := WaitFor(ctx, db, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitFor for a missing value = %v, want DeadlineExceeded", err)
+	}
+	db.Close()
+	if err := WaitFor(context.Background(), db, 3); !errors.Is(err, ErrClosed) {
+		t.Fatalf("WaitFor after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestFrontIsA(t *testing.T) {
+	db := New(0, 0)
+	for i := 0; i < 4; i++ {
+		if got, want := db.FrontIsA(), i%2 == 1; got != want {
+			t.Fatalf("FrontIsA after %d swaps = %t, want %t", i, got, want)
+		}
+		db.Publish(context.Background(), i)
+		db.Next()
+	}
+}
+
+func TestObserve(t *testing.T) {
+	db := New(0, 1)
+	if v, gen, pending := db.Observe(); v != 1 || gen != 0 || pending {
+		t.Fatalf("Observe = %d, %d, %t; want 1, 0, false", v, gen, pending)
+	}
+	db.Publish(context.Background(), 2)
+	if v, gen, pending := db.Observe(); v != 1 || gen != 0 || !pending {
+		t.Fatalf("Observe with a frame pending = %d, %d, %t; want 1, 0, true", v, gen, pending)
+	}
+	db.Next()
+	if v, gen, pending := db.Observe(); v != 2 || gen != 1 || pending {
+		t.Fatalf("Observe after the swap = %d, %d, %t; want 2, 1, false", v, gen, pending)
+	}
+}
@@ -1,3 +1,3 @@
 module github.com/jncornett/doublebuf
 
-go 1.20
+go 1.23
@@ -0,0 +1,54 @@
+package doublebuf
+
+// WithHistory makes the DoubleBuffer keep copies of the last k values
+// promoted to the front by swaps, for looking back at what a consumer
+// displayed, e.g. when debugging flicker in a renderer. The copies are
+// taken at swap time, so they stay valid however the buffers are reused;
+// for pointer, slice or map types only the reference is copied. Memory is
+// bounded by the ring of k values. The initial front and values written by
+// CompareAndSwapFront or TakeFront are not recorded. k <= 0 disables the
+// history, which is the default.
+func WithHistory[T any](k int) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		if k <= 0 {
+			db.history = nil
+			return
+		}
+		db.history = &history[T]{ring: make([]T, k)}
+	}
+}
+
+// History returns copies of the last values promoted to the front, oldest
+// first, at most as many as configured by WithHistory. It returns nil
+// without WithHistory.
+// History is safe to call concurrently with Next.
+func (db *DoubleBuffer[T]) History() []T {
+	if db.history == nil {
+		return nil
+	}
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	return db.history.values()
+}
+
+// history is a ring of the last front values; it is guarded by db.swapMu.
+type history[T any] struct {
+	ring []T
+	n    int // number of values recorded, up to len(ring)
+	head int // index of the next value to record
+}
+
+func (h *history[T]) record(t T) {
+	h.ring[h.head] = t
+	h.head = (h.head + 1) % len(h.ring)
+	h.n = min(h.n+1, len(h.ring))
+}
+
+func (h *history[T]) values() []T {
+	vs := make([]T, 0, h.n)
+	start := (h.head - h.n + len(h.ring)) % len(h.ring)
+	for i := 0; i < h.n; i++ {
+		vs = append(vs, h.ring[(start+i)%len(h.ring)])
+	}
+	return vs
+}
@@ -0,0 +1,29 @@
+package doublebuf
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestWithHistory(t *testing.T) {
+	if h := New(0, 0).History(); h != nil {
+		t.Fatalf("History without WithHistory = %v, want nil", h)
+	}
+	db := New(0, 0, WithHistory[int](3))
+	if h := db.History(); len(h) != 0 {
+		t.Fatalf("History before the first swap = %v, want empty", h)
+	}
+	for i := 1; i <= 5; i++ {
+		db.Publish(context.Background(), i)
+		db.Next()
+		if i == 2 {
+			if h := db.History(); !slices.Equal(h, []int{1, 2}) {
+				t.Fatalf("History = %v, want [1 2]", h)
+			}
+		}
+	}
+	if h := db.History(); !slices.Equal(h, []int{3, 4, 5}) {
+		t.Fatalf("History = %v, want [3 4 5]", h)
+	}
+}
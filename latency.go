@@ -0,0 +1,73 @@
+package doublebuf
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// latencyBounds are the exclusive upper bounds of the latency buckets but
+// the last, which has no upper bound.
+var latencyBounds = [...]time.Duration{
+	time.Microsecond,
+	4 * time.Microsecond,
+	16 * time.Microsecond,
+	64 * time.Microsecond,
+	256 * time.Microsecond,
+	time.Millisecond,
+	4 * time.Millisecond,
+	16 * time.Millisecond,
+	64 * time.Millisecond,
+	256 * time.Millisecond,
+	time.Second,
+}
+
+// histogram counts latencies in the buckets of latencyBounds.
+type histogram [len(latencyBounds) + 1]atomic.Uint64
+
+func (h *histogram) record(d time.Duration) {
+	i := 0
+	for i < len(latencyBounds) && d >= latencyBounds[i] {
+		i++
+	}
+	h[i].Add(1)
+}
+
+// WithLatencyHistogram makes the DoubleBuffer record how long each frame
+// waited between being readied and being swapped in, in a histogram of fixed
+// buckets read with LatencyBuckets. Every frame is then stamped when it is
+// readied, as by ReadyAt, so NextResult reports the stamp as well. Recording
+// costs a reading of the clock on Ready and one atomic increment on each
+// swap, and never allocates; without the option nothing is recorded.
+func WithLatencyHistogram[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.latency = new(histogram) }
+}
+
+// LatencyBucketBounds returns the upper bounds of the buckets of
+// LatencyBuckets: bucket i counts latencies below bound i and at least
+// bound i-1. The last bucket, which has no upper bound, counts latencies of
+// at least the last bound, one second.
+func LatencyBucketBounds() []time.Duration { return latencyBounds[:] }
+
+// LatencyBuckets returns the counts of the latency histogram of
+// WithLatencyHistogram, one more than there are LatencyBucketBounds, or nil
+// without the option. The counts are read one at a time, so a swap that
+// happens concurrently may be reflected in some of them and not others.
+func (db *DoubleBuffer[T]) LatencyBuckets() []uint64 {
+	if db.latency == nil {
+		return nil
+	}
+	counts := make([]uint64, len(db.latency))
+	for i := range db.latency {
+		counts[i] = db.latency[i].Load()
+	}
+	return counts
+}
+
+// stamp returns the time to stamp a frame with when it is readied: readyAt,
+// or the current time if the frame is unstamped and latencies are recorded.
+func (db *DoubleBuffer[T]) stamp(readyAt time.Time) time.Time {
+	if readyAt.IsZero() && db.latency != nil {
+		return db.now()
+	}
+	return readyAt
+}
@@ -0,0 +1,27 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithLatencyHistogram(t *testing.T) {
+	if got := New(0, 0).LatencyBuckets(); got != nil {
+		t.Fatalf("LatencyBuckets without the option = %v, want nil", got)
+	}
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now), WithLatencyHistogram[int]())
+	for _, d := range []time.Duration{0, 2 * time.Microsecond, 2 * time.Second} {
+		db.Publish(context.Background(), 1)
+		clock.advance(d)
+		db.Next()
+	}
+	got := db.LatencyBuckets()
+	if len(got) != len(LatencyBucketBounds())+1 {
+		t.Fatalf("got %d buckets, want %d", len(got), len(LatencyBucketBounds())+1)
+	}
+	if got[0] != 1 || got[1] != 1 || got[len(got)-1] != 1 {
+		t.Fatalf("LatencyBuckets = %v, want one each in the first, second and last bucket", got)
+	}
+}
@@ -0,0 +1,37 @@
+package doublebuf
+
+import "errors"
+
+// ErrTooLong is returned by ReadyContext when WithMaxLen rejects a frame.
+var ErrTooLong = errors.New("doublebuf: frame exceeds maximum length")
+
+// WithMaxLen caps the length of slice-typed frames at n, so that a single
+// oversized frame cannot balloon the buffers or reach the consumer.
+// A frame whose length exceeds n when it is readied is not published
+// and is counted by Rejected. The producer learns about the rejection from
+// ReadyContext, which returns ErrTooLong; with plain Ready the rejection is
+// silent, but the producer keeps the back buffer and gets it back from the
+// next Back, where it can truncate or replace the frame.
+// The type argument must be given explicitly: WithMaxLen[[]byte](n).
+func WithMaxLen[S ~[]E, E any](n int) Option[S] {
+	return func(db *DoubleBuffer[S]) {
+		db.check = func(s *S) error {
+			if len(*s) > n {
+				return ErrTooLong
+			}
+			return nil
+		}
+	}
+}
+
+// validate checks a frame about to be readied, counting rejections.
+func (db *DoubleBuffer[T]) validate(p *T) error {
+	if db.check == nil {
+		return nil
+	}
+	err := db.check(p)
+	if err != nil {
+		db.rejected.Add(1)
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithMaxLen(t *testing.T) {
+	db := New(nil, nil, WithMaxLen[[]byte](4))
+	back, _ := db.Back(context.Background())
+	*back = append(*back, "too long"...)
+	if err := db.ReadyContext(context.Background()); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("ReadyContext = %v, want ErrTooLong", err)
+	}
+	if _, changed := db.Next(); changed {
+		t.Fatal("rejected frame was published")
+	}
+	if got := db.Rejected(); got != 1 {
+		t.Fatalf("Rejected = %d, want 1", got)
+	}
+	again, _ := db.Back(context.Background())
+	if again != back {
+		t.Fatal("producer lost its back buffer after a rejected frame")
+	}
+	*again = (*again)[:4]
+	db.Ready()
+	if v, changed := db.Next(); !changed || string(v) != "too " {
+		t.Fatalf("Next = %q, %t; want \"too \", true", v, changed)
+	}
+}
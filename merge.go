@@ -0,0 +1,61 @@
+package doublebuf
+
+// WithMergeOnOverwrite makes a Ready that finds a readied frame still
+// pending merge the new frame into it, instead of replacing it and dropping
+// the pending frame, for accumulate-while-waiting semantics such as summing
+// counters until the consumer swaps. merge is called with the pending frame
+// and the incoming one and must fold incoming into pending: the pending
+// buffer survives and stays pending, keeping its ReadyAt stamp, while the
+// incoming buffer returns to the free list, so neither pointer may be
+// retained. Merged frames are not counted by Dropped.
+// A pending frame can only be overwritten with extra buffers, see
+// WithBuffers, so merge is never called without them, nor in backpressure
+// mode. merge runs on the producer's goroutine while the pending frame is
+// withheld from the consumer, so Next does not see a half-merged frame. If
+// merge panics, the pending frame is published again as it is, and the
+// incoming frame is dropped, or stays with the producer as its back buffer
+// if the panic propagates to Ready. With a nil merge, the pending frame is
+// replaced as without the option.
+func WithMergeOnOverwrite[T any](merge func(pending, incoming *T)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.merge = merge }
+}
+
+// publishMerge publishes s like publish, merging it into the pending buffer
+// if there is one, see WithMergeOnOverwrite.
+func (db *DoubleBuffer[T]) publishMerge(s *slot[T]) {
+	for {
+		pending := db.next.Swap(nil)
+		if pending == nil {
+			if db.next.CompareAndSwap(nil, s) {
+				return
+			}
+			continue // another producer published in between
+		}
+		db.mergeInto(pending, s)
+		s = pending
+	}
+}
+
+// mergeInto merges incoming into pending and recycles incoming, or drops it
+// if merge panicked and the panic handler recovered.
+func (db *DoubleBuffer[T]) mergeInto(pending, incoming *slot[T]) {
+	merged, returned := false, false
+	defer func() {
+		if !returned { // merge panicked, and the producer keeps incoming
+			if old := db.next.Swap(pending); old != nil {
+				db.dropped.Add(1)
+				db.prev <- old
+			}
+			db.readied.notify()
+		}
+	}()
+	db.invoke("MergeOnOverwrite", func() {
+		db.merge(pending.p, incoming.p)
+		merged = true
+	})
+	returned = true
+	if !merged {
+		db.dropped.Add(1)
+	}
+	db.prev <- incoming
+}
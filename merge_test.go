@@ -0,0 +1,48 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithMergeOnOverwrite(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithMergeOnOverwrite(func(pending, incoming *int) {
+		*pending += *incoming
+	}))
+	for i := 1; i <= 3; i++ {
+		back, ok := db.TryBack()
+		if !ok {
+			t.Fatalf("TryBack %d blocked, merged buffer not recycled", i)
+		}
+		*back = i
+		db.Ready()
+	}
+	if v, changed := db.Next(); !changed || v != 6 {
+		t.Fatalf("Next = %d, %t; want 6, true", v, changed)
+	}
+	if got := db.Dropped(); got != 0 {
+		t.Fatalf("Dropped = %d, want 0", got)
+	}
+	db.Publish(context.Background(), 4)
+	if v, changed := db.Next(); !changed || v != 4 {
+		t.Fatalf("Next after merging = %d, %t; want 4, true", v, changed)
+	}
+}
+
+func TestWithMergeOnOverwritePanic(t *testing.T) {
+	var recovered error
+	db := New(0, 0, WithBuffers(0),
+		WithPanicHandler[int](func(err error) { recovered = err }),
+		WithMergeOnOverwrite(func(pending, incoming *int) { panic("boom") }))
+	db.Publish(context.Background(), 1)
+	db.Publish(context.Background(), 2)
+	if recovered == nil {
+		t.Fatal("panic in merge not reported")
+	}
+	if v, changed := db.Next(); !changed || v != 1 {
+		t.Fatalf("Next = %d, %t; want the pending frame 1", v, changed)
+	}
+	if got := db.Dropped(); got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
@@ -0,0 +1,97 @@
+package doublebuf
+
+import (
+	"context"
+	"time"
+)
+
+// WithMultiProducer allows several producer goroutines to fill and ready
+// buffers concurrently, each holding its own distinct back buffer.
+// In multi-producer mode producers use AcquireBack and ReadyBack instead of
+// Back, TryBack and Ready, which panic.
+// Buffers handed out are tracked in a set guarded by a mutex, so every
+// AcquireBack and ReadyBack costs an uncontended lock in the common case.
+// Each producer holds a buffer while filling it, so combine this with
+// WithBuffers to have at least one buffer per producer plus the front one,
+// otherwise producers queue up in AcquireBack.
+func WithMultiProducer[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		db.multiProducer = true
+		db.held = make(map[*T]*slot[T])
+	}
+}
+
+// AcquireBack hands out a free back buffer exclusively to the caller,
+// blocking until the consumer frees one, ctx is done, or the DoubleBuffer is
+// closed, in which case it returns ErrClosed.
+// Unlike Back, every call returns a distinct buffer, which the caller must
+// eventually pass to ReadyBack.
+// AcquireBack is safe to call concurrently with all other methods.
+// It panics unless the DoubleBuffer was created with WithMultiProducer.
+func (db *DoubleBuffer[T]) AcquireBack(ctx context.Context) (*T, error) {
+	db.checkMultiProducer("AcquireBack")
+	if db.closed.Load() {
+		return nil, ErrClosed
+	}
+	back, _, err := db.recvBack(ctx)
+	if err != nil {
+		return nil, err
+	}
+	db.heldMu.Lock()
+	db.held[back.p] = back
+	db.heldMu.Unlock()
+	db.checkout(back)
+	db.backs.Add(1)
+	return back.p, nil
+}
+
+// ReadyBack readies a buffer obtained from AcquireBack, making it the buffer
+// promoted by the next swap. After ReadyBack the caller no longer owns back.
+// ReadyBack discards back once the DoubleBuffer has been closed.
+// In backpressure mode ReadyBack blocks until the pending frame is consumed.
+// A frame rejected by WithMaxLen or throttled by WithMaxReadyRate is
+// recycled instead of published.
+// ReadyBack is safe to call concurrently with all other methods.
+// It panics if back is not currently held by a producer, or unless the
+// DoubleBuffer was created with WithMultiProducer.
+func (db *DoubleBuffer[T]) ReadyBack(back *T) {
+	db.checkMultiProducer("ReadyBack")
+	db.heldMu.Lock()
+	s, ok := db.held[back]
+	delete(db.held, back)
+	db.heldMu.Unlock()
+	if !ok {
+		panic("doublebuf: ReadyBack called with a buffer not obtained from AcquireBack")
+	}
+	db.inFlight.Add(-1)
+	if db.closed.Load() {
+		db.returned.notify()
+		return
+	}
+	if db.validate(back) != nil || db.throttle() {
+		db.prev <- s // recycle the rejected frame
+		return
+	}
+	s.readyAt = db.stamp(time.Time{})
+	readyAt := s.readyAt
+	old, err := db.publish(context.Background(), s, false)
+	if err == nil {
+		db.readys.Add(1)
+		db.tapReady(readyAt)
+	}
+	if old != nil {
+		db.drop(old, false)
+	}
+}
+
+func (db *DoubleBuffer[T]) checkSingleProducer(method string) {
+	if db.multiProducer {
+		panic("doublebuf: " + method + " called on a multi-producer DoubleBuffer")
+	}
+}
+
+func (db *DoubleBuffer[T]) checkMultiProducer(method string) {
+	if !db.multiProducer {
+		panic("doublebuf: " + method + " requires WithMultiProducer")
+	}
+}
@@ -0,0 +1,55 @@
+package doublebuf
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestMultiProducer(t *testing.T) {
+	const producers, frames = 4, 1000
+	db := New(0, 0, WithBuffers(0, 0, 0, 0), WithMultiProducer[int]())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 1; j <= frames; j++ {
+				back, err := db.AcquireBack(ctx)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				*back = j
+				db.ReadyBack(back)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if v, changed := db.Next(); changed && (v < 1 || v > frames) {
+			t.Fatalf("Next = %d, want a produced frame", v)
+		}
+	}
+}
+
+func TestMultiProducerMisuse(t *testing.T) {
+	db := New(0, 0, WithMultiProducer[int]())
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Back did not panic in multi-producer mode")
+		}
+	}()
+	db.Back(context.Background())
+}
@@ -0,0 +1,86 @@
+package doublebuf
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// notifier wakes goroutines waiting for a state change.
+// The zero value is ready to use.
+// notify is cheap when nobody is waiting, so it is safe to call on the hot path.
+type notifier struct {
+	armed atomic.Bool
+	one   bool // wake a single waiter per notify, see WithWakeOne
+	mu    sync.Mutex
+	ch    chan struct{}
+	queue []chan struct{} // waiters in order of arrival if one is set
+}
+
+// wait returns a channel that is closed by the next call to notify.
+// Callers must obtain the channel before checking the condition they are
+// waiting for, otherwise a notification may be missed.
+// If the notifier wakes a single waiter, callers that stop waiting without
+// having received the channel must pass it to release.
+func (n *notifier) wait() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.one {
+		ch := make(chan struct{})
+		n.queue = append(n.queue, ch)
+		n.armed.Store(true)
+		return ch
+	}
+	if n.ch == nil {
+		n.ch = make(chan struct{})
+		n.armed.Store(true)
+	}
+	return n.ch
+}
+
+// notify wakes all goroutines waiting on a channel returned by wait, or
+// only the longest waiting one if the notifier wakes a single waiter.
+func (n *notifier) notify() {
+	if !n.armed.Load() {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifyLocked()
+}
+
+func (n *notifier) notifyLocked() {
+	if n.one {
+		if len(n.queue) > 0 {
+			close(n.queue[0])
+			n.queue[0] = nil
+			n.queue = n.queue[1:]
+		}
+		n.armed.Store(len(n.queue) > 0)
+		return
+	}
+	if n.ch != nil {
+		close(n.ch)
+		n.ch = nil
+		n.armed.Store(false)
+	}
+}
+
+// release gives up waiting on ch. If ch was notified already, the wakeup is
+// passed on to the next waiter, so it is not lost on a goroutine that did
+// not act on it. release is a no-op unless the notifier wakes a single
+// waiter.
+func (n *notifier) release(ch <-chan struct{}) {
+	if !n.one {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, q := range n.queue {
+		if q == ch {
+			n.queue = append(n.queue[:i], n.queue[i+1:]...)
+			n.armed.Store(len(n.queue) > 0)
+			return
+		}
+	}
+	n.notifyLocked()
+}
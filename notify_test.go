@@ -0,0 +1,82 @@
+package doublebuf
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestNotifierWakeOne(t *testing.T) {
+	n := notifier{one: true}
+	a, b := n.wait(), n.wait()
+	n.notify()
+	select {
+	case <-a:
+	default:
+		t.Fatal("longest waiter not woken")
+	}
+	select {
+	case <-b:
+		t.Fatal("second waiter woken by a single notify")
+	default:
+	}
+
+	// A woken waiter that gives up passes the wakeup on.
+	c := n.wait()
+	n.release(a)
+	select {
+	case <-b:
+	case <-time.After(time.Second):
+		t.Fatal("released wakeup not passed on")
+	}
+	n.release(c)
+	if n.armed.Load() {
+		t.Fatal("notifier armed with no waiters left")
+	}
+}
+
+// BenchmarkWake measures waking one of several goroutines waiting on a
+// notifier, when every notify wakes all of them or only one.
+func BenchmarkWake(b *testing.B) {
+	const waiters = 4
+	for _, bc := range []struct {
+		name string
+		one  bool
+	}{
+		{"Broadcast", false},
+		{"One", true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			n := &notifier{one: bc.one}
+			stop := make(chan struct{})
+			defer close(stop)
+			ack := make(chan struct{}, 1)
+			for i := 0; i < waiters; i++ {
+				go func() {
+					for {
+						ch := n.wait()
+						select {
+						case <-stop:
+							n.release(ch)
+							return
+						case <-ch:
+						}
+						select {
+						case ack <- struct{}{}:
+						default:
+						}
+					}
+				}()
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for !n.armed.Load() {
+					runtime.Gosched()
+				}
+				n.notify()
+				<-ack
+			}
+		})
+	}
+}
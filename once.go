@@ -0,0 +1,20 @@
+package doublebuf
+
+// NewOnce creates a DoubleBuffer for handing over a single value, like a
+// future: the producer fills the back buffer and readies it once, and the
+// consumer swaps it in once.
+//
+// The first successful Ready also closes the DoubleBuffer, as CloseDrain
+// would, so the readied value stays available. From then on Back returns
+// ErrClosed, ReadyContext returns ErrClosed and Ready is a no-op. Next
+// returns the value with changed set to true exactly once; after that the
+// DoubleBuffer is closed and drained, so Next keeps returning the value with
+// changed set to false, NextErr returns ErrClosed and Frames ends, see the
+// package documentation. Close before the value is readied ends the exchange
+// without a value.
+func NewOnce[T comparable]() *DoubleBuffer[T] {
+	var zero T
+	db := New(zero, zero)
+	db.once = true
+	return db
+}
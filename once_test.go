@@ -0,0 +1,29 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNewOnce(t *testing.T) {
+	db := NewOnce[int]()
+	back, err := db.Back(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	*back = 42
+	db.Ready()
+	if _, err := db.Back(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Back after the first Ready = %v, want ErrClosed", err)
+	}
+	if err := db.ReadyContext(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("ReadyContext after the first Ready = %v, want ErrClosed", err)
+	}
+	if v, changed, err := db.NextErr(); v != 42 || !changed || err != nil {
+		t.Fatalf("NextErr = %d, %t, %v; want 42, true, nil", v, changed, err)
+	}
+	if v, changed, err := db.NextErr(); v != 42 || changed || !errors.Is(err, ErrClosed) {
+		t.Fatalf("second NextErr = %d, %t, %v; want 42, false, ErrClosed", v, changed, err)
+	}
+}
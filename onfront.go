@@ -0,0 +1,13 @@
+package doublebuf
+
+// OnFront calls f with the front buffer if it holds a value of type C, and
+// reports whether it did. It is meant for DoubleBuffers of an interface type
+// carrying heterogeneous events, where each consumer only handles some
+// concrete types.
+func OnFront[T any, C any](db *DoubleBuffer[T], f func(C)) bool {
+	c, ok := any(db.Front()).(C)
+	if ok {
+		f(c)
+	}
+	return ok
+}
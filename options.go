@@ -0,0 +1,124 @@
+package doublebuf
+
+import "time"
+
+// Option configures a DoubleBuffer created by New.
+// Options that do not depend on T must be instantiated explicitly,
+// e.g. WithMultiProducer[int]().
+// A nil Option is ignored. Options that take a function treat a nil
+// function as if the option had not been given, so a nil hook can never
+// cause a panic later in Ready or Next; each option documents its default.
+type Option[T any] func(*DoubleBuffer[T])
+
+// WithBuffers adds backing buffers beyond the two passed to New.
+// With extra buffers the producer no longer has to wait for the consumer to
+// swap before it can start on the next frame: a Ready that finds a readied
+// frame still pending replaces it, and the replaced frame is dropped and its
+// buffer reused. New(a, b, WithBuffers(c)) is classic triple buffering.
+func WithBuffers[T any](bufs ...T) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.extra = append(db.extra, bufs...) }
+}
+
+// WithClock makes the DoubleBuffer read the current time from now instead of
+// time.Now, everywhere it needs the time: LastSwap, StalledFor and
+// BackWaitTotal. Tests can inject a controllable clock to exercise
+// time-dependent behavior without sleeping. A nil now selects time.Now.
+func WithClock[T any](now func() time.Time) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		if now == nil {
+			now = time.Now
+		}
+		db.now = now
+	}
+}
+
+// WithOnBackCancel registers fn to be called with the context's error
+// whenever a producer waiting in Back or AcquireBack gives up because its
+// context is done, right before the error is returned, e.g. to log
+// cancellations during shutdown. Each such call is also counted by
+// BackCancelled. fn runs on the producer's goroutine. Cancellations are still
+// counted if fn is nil.
+func WithOnBackCancel[T any](fn func(error)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.onBackCancel = fn }
+}
+
+// WithBackSpin makes Back, BackTimed and AcquireBack poll the free list up
+// to n times, yielding the processor in between, before they block waiting
+// for the consumer to hand back a buffer. A producer in a tight loop then
+// often picks up the buffer without the cost of blocking and being woken,
+// trading a little CPU for lower latency under high throughput. The time
+// spent spinning counts as waiting in BackWaitTotal. The default, n <= 0,
+// blocks right away.
+func WithBackSpin[T any](n int) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.backSpin = n }
+}
+
+// WithReadyTap registers fn to be called on every Ready, ReadyAt,
+// ReadyContext or ReadyBack that publishes a frame, with the time the frame
+// was readied, to trace the producer: combined with the times of the swaps,
+// e.g. from NextResult, it gives the full timeline of production and
+// consumption. fn is called whether the frame is eventually swapped in or
+// dropped, but not for a frame rejected by WithMaxLen or readied after
+// close. The time is the stamp of ReadyAt if the frame carries one, and is
+// otherwise read from the clock of WithClock. fn runs on the producer's
+// goroutine, right after the frame is published, and should be cheap. A nil
+// fn traces nothing.
+func WithReadyTap[T any](fn func(readyAt time.Time)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.readyTap = fn }
+}
+
+// WithWakeOne makes each published frame wake a single goroutine waiting for
+// one in Frames or FrontNewerThan, the one that has waited longest, instead
+// of all of them. With several such consumers the others would only find the
+// frame taken and go back to sleep, so waking one saves that work. A consumer
+// that stops waiting after being woken passes the wakeup on, so no frame is
+// left pending while consumers sleep. Closing the DoubleBuffer still wakes
+// every waiter.
+// By default every waiter is woken, which costs nothing extra when there is
+// only one; WithWakeOne allocates a little per wait to queue the waiters.
+// Barrier consumers, see RegisterConsumer, are not affected.
+func WithWakeOne[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.readied.one = true }
+}
+
+// WithPrevious makes every swap copy the front value it retires, for
+// Previous. The copy costs time proportional to the size of T on each swap,
+// which is why Previous reports nothing without the option.
+func WithPrevious[T any]() Option[T] {
+	return func(db *DoubleBuffer[T]) { db.keepPrev = true }
+}
+
+// WithOnDrop registers fn to be called with every frame counted by Dropped,
+// e.g. to release resources that the frame holds, right before its buffer
+// returns to the free list. fn runs as frames are dropped, mostly on the
+// producer's goroutine in Ready. A nil fn recycles dropped frames as they
+// are.
+func WithOnDrop[T any](fn func(*T)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.onDrop = fn }
+}
+
+// WithOnRecycle registers fn to be called whenever a buffer is handed from
+// the free list to a producer by Back, TryBack or AcquireBack, right before
+// the producer gets it. It is the central place to reset or re-initialize
+// reused buffers. fn runs on the producer's goroutine and is not called for
+// the initial back buffer, nor on calls to Back that return the buffer the
+// producer already holds. With a nil fn, recycled buffers are handed out
+// as they are.
+func WithOnRecycle[T any](fn func(*T)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.onRecycle = fn }
+}
+
+// tapReady calls the hook of WithReadyTap for a frame readied at readyAt,
+// or now if the frame carries no stamp, and counts the frame for WithRates.
+func (db *DoubleBuffer[T]) tapReady(readyAt time.Time) {
+	if db.rates != nil {
+		db.rates.produce.tick(db.sinceEpoch())
+	}
+	if db.readyTap == nil {
+		return
+	}
+	if readyAt.IsZero() {
+		readyAt = db.now()
+	}
+	db.invoke("ReadyTap", func() { db.readyTap(readyAt) })
+}
@@ -0,0 +1,42 @@
+package doublebuf
+
+import (
+	"context"
+	"iter"
+)
+
+// Producer is the producer side of a DoubleBuffer created by NewPair.
+type Producer[T any] struct{ db *DoubleBuffer[T] }
+
+// Consumer is the consumer side of a DoubleBuffer created by NewPair.
+type Consumer[T any] struct{ db *DoubleBuffer[T] }
+
+// NewPair creates a DoubleBuffer like New and returns role-specific handles
+// to it, so each can be handed to the goroutine playing that role without
+// giving it access to the other side's methods. Both handles share the same
+// underlying DoubleBuffer.
+func NewPair[T any](a, b T, opts ...Option[T]) (*Producer[T], *Consumer[T]) {
+	db := New(a, b, opts...)
+	return &Producer[T]{db}, &Consumer[T]{db}
+}
+
+// Back is DoubleBuffer.Back.
+func (p *Producer[T]) Back(ctx context.Context) (*T, error) { return p.db.Back(ctx) }
+
+// TryBack is DoubleBuffer.TryBack.
+func (p *Producer[T]) TryBack() (*T, bool) { return p.db.TryBack() }
+
+// Ready is DoubleBuffer.Ready.
+func (p *Producer[T]) Ready() { p.db.Ready() }
+
+// ReadyContext is DoubleBuffer.ReadyContext.
+func (p *Producer[T]) ReadyContext(ctx context.Context) error { return p.db.ReadyContext(ctx) }
+
+// Front is DoubleBuffer.Front.
+func (c *Consumer[T]) Front() T { return c.db.Front() }
+
+// Next is DoubleBuffer.Next.
+func (c *Consumer[T]) Next() (T, bool) { return c.db.Next() }
+
+// Frames is DoubleBuffer.Frames.
+func (c *Consumer[T]) Frames(ctx context.Context) iter.Seq[T] { return c.db.Frames(ctx) }
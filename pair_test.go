@@ -0,0 +1,22 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewPair(t *testing.T) {
+	p, c := NewPair(0, 0)
+	back, err := p.Back(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	*back = 1
+	p.Ready()
+	if v, changed := c.Next(); !changed || v != 1 {
+		t.Fatalf("Next = %d, %t; want 1, true", v, changed)
+	}
+	if v := c.Front(); v != 1 {
+		t.Fatalf("Front = %d, want 1", v)
+	}
+}
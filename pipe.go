@@ -0,0 +1,37 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+)
+
+// Pipe connects two DoubleBuffers as a stage of a processing pipeline: it
+// swaps in each frame of src as Frames does, and publishes f of it into dst
+// as Publish does, until ctx is done or one of the buffers is closed. It is
+// meant to run on its own goroutine, as the consumer of src and the producer
+// of dst.
+//
+// Pipe forwards the end of the stream: once src is closed and drained, Pipe
+// closes dst with CloseDrain, so the last frame still reaches the consumer of
+// dst, and returns nil; if src was closed with CloseWithError, dst is closed
+// with the same error, which Pipe returns as reported by src. If dst is
+// closed first, Pipe stops and returns ErrClosed, leaving src to its owner.
+// If ctx is done, Pipe returns ctx.Err() and leaves both buffers open.
+func Pipe[T, U any](ctx context.Context, src *DoubleBuffer[T], dst *DoubleBuffer[U], f func(T) U) error {
+	for {
+		v, err := src.nextWait(ctx)
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(err, ErrClosed):
+			return dst.CloseDrain(ctx)
+		default:
+			dst.CloseWithError(src.Err())
+			return err
+		}
+		if err := dst.Publish(ctx, f(v)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	src, dst := New(0, 0), New("", "")
+	errc := make(chan error, 1)
+	go func() { errc <- Pipe(context.Background(), src, dst, strconv.Itoa) }()
+	go func() {
+		for i := 1; i <= 3; i++ {
+			src.Publish(context.Background(), i)
+		}
+		src.CloseDrain(context.Background())
+	}()
+	var got []string
+	for v := range dst.Frames(context.Background()) {
+		got = append(got, v)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[2] != "3" {
+		t.Fatalf("dst yielded %q, want [1 2 3]", got)
+	}
+}
+
+func TestPipeError(t *testing.T) {
+	failure := errors.New("producer failed")
+	src, dst := New(0, 0), New(0, 0)
+	src.CloseWithError(failure)
+	if err := Pipe(context.Background(), src, dst, func(v int) int { return v }); !errors.Is(err, failure) {
+		t.Fatalf("Pipe = %v, want the error of src", err)
+	}
+	if err := dst.Err(); err != failure {
+		t.Fatalf("dst closed with %v, want %v", err, failure)
+	}
+}
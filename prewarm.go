@@ -0,0 +1,32 @@
+package doublebuf
+
+// Prewarm runs the swap and handoff machinery once without publishing
+// anything, so that the first real swap does not pay for cold caches,
+// page faults or first-use initialization in the runtime, which shows up in
+// tail latencies. The DoubleBuffer does not allocate lazily itself, so
+// Prewarm only touches what a swap touches. It leaves the DoubleBuffer in
+// exactly the logical state it was in.
+// Prewarm must be called during setup, before producers and consumers start.
+func (db *DoubleBuffer[T]) Prewarm() {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	front := db.front.Load()
+	db.front.Store(front)
+	db.next.CompareAndSwap(nil, nil)
+	// Round-trip a buffer through the free list, as a swap followed by Back does.
+	if len(db.prev) > 0 {
+		db.prev <- <-db.prev
+	} else {
+		db.prev <- front
+		<-db.prev
+	}
+	if db.keepPrev {
+		// Copy the front like a swap copies the retired value.
+		saved := db.previous
+		db.previous = *front.p
+		db.previous = saved
+	}
+	_ = db.now()
+	db.swapped.notify()
+	db.readied.notify()
+}
@@ -0,0 +1,53 @@
+package doublebuf
+
+import (
+	"context"
+	"sync"
+)
+
+// Pump runs a producer and a consumer over a new DoubleBuffer with a and b
+// as its buffers, for callers that only want to say how to produce a frame
+// and how to consume one. A producer goroutine repeatedly calls produce with
+// the back buffer and readies it; the calling goroutine calls consume with
+// each frame swapped in, as Frames yields them.
+//
+// Pump returns once both loops have stopped:
+//   - If produce returns an error, no further frames are produced, the
+//     frames readied before are still consumed, and Pump returns the error.
+//   - If ctx is done, both loops stop and Pump returns ctx.Err(). A call to
+//     produce or consume in progress is waited for, so produce should
+//     observe ctx itself if it may block.
+//
+// Pump never leaves a goroutine behind.
+func Pump[T any](ctx context.Context, a, b T, produce func(*T) error, consume func(T)) error {
+	db := New(a, b)
+	inner, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var produceErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			back, err := db.Back(inner)
+			if err != nil {
+				return
+			}
+			if err := produce(back); err != nil {
+				produceErr = err
+				db.CloseDrain(inner)
+				return
+			}
+			db.Ready()
+		}
+	}()
+	for v := range db.Frames(inner) {
+		consume(v)
+	}
+	cancel() // stop the producer if the consumer stopped first
+	wg.Wait()
+	if produceErr != nil {
+		return produceErr
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,37 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPump(t *testing.T) {
+	done := errors.New("done")
+	n := 0
+	var got []int
+	err := Pump(context.Background(), 0, 0, func(p *int) error {
+		if n == 3 {
+			return done
+		}
+		n++
+		*p = n
+		return nil
+	}, func(v int) { got = append(got, v) })
+	if !errors.Is(err, done) {
+		t.Fatalf("Pump = %v, want the error of produce", err)
+	}
+	if len(got) != 3 || got[2] != 3 {
+		t.Fatalf("consumed %v, want [1 2 3]", got)
+	}
+}
+
+func TestPumpContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := Pump(ctx, 0, 0, func(p *int) error { *p++; return nil }, func(int) {})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Pump = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,150 @@
+package doublebuf
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// WithRates makes the DoubleBuffer estimate its throughput, reported by
+// ProduceRate and ConsumeRate, e.g. for autoscaling decisions. The rates
+// are exponentially weighted moving averages with a time constant of
+// window, which defaults to one second if window <= 0: a change in
+// throughput is reflected to about 63% after window, and an event from k
+// windows ago weighs e^-k as much as a current one. The estimate is updated
+// lock-free on every event, at the cost of reading the clock of WithClock
+// in Ready and one exponential per event.
+func WithRates[T any](window time.Duration) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		if window <= 0 {
+			window = time.Second
+		}
+		db.rates = &rates{
+			produce: rate{tau: window.Seconds()},
+			consume: rate{tau: window.Seconds()},
+		}
+	}
+}
+
+// ProduceRate returns the estimated number of frames that producers ready
+// per second, counting every Ready that publishes a frame, including those
+// dropped later, see WithRates. It returns 0 without WithRates. The value is
+// approximate: it decays as time passes without frames, and concurrent
+// producers may slightly skew it.
+// ProduceRate is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) ProduceRate() float64 {
+	if db.rates == nil {
+		return 0
+	}
+	return db.rates.produce.read(db.sinceEpoch())
+}
+
+// ConsumeRate returns the estimated number of swaps per second, see
+// WithRates, or 0 without WithRates. Like ProduceRate, it is approximate.
+// ConsumeRate is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) ConsumeRate() float64 {
+	if db.rates == nil {
+		return 0
+	}
+	return db.rates.consume.read(db.sinceEpoch())
+}
+
+func (db *DoubleBuffer[T]) sinceEpoch() int64 { return int64(db.now().Sub(db.epoch)) }
+
+type rates struct {
+	produce, consume rate
+}
+
+// rate is an event rate estimated as an exponentially decaying sum of
+// events divided by the time constant tau, which converges to the event
+// rate for evenly spaced events.
+type rate struct {
+	tau  float64       // in seconds
+	last atomic.Int64  // time of the last event, in nanoseconds since the epoch
+	bits atomic.Uint64 // estimate at the last event, as float64 bits
+}
+
+// tick records an event at now, in nanoseconds since the epoch.
+func (r *rate) tick(now int64) {
+	decay := r.decay(now - r.last.Swap(now))
+	for {
+		old := r.bits.Load()
+		v := math.Float64frombits(old)*decay + 1/r.tau
+		if r.bits.CompareAndSwap(old, math.Float64bits(v)) {
+			return
+		}
+	}
+}
+
+// read returns the estimate at now, in nanoseconds since the epoch.
+func (r *rate) read(now int64) float64 {
+	v := math.Float64frombits(r.bits.Load())
+	return v * r.decay(now-r.last.Load())
+}
+
+func (r *rate) decay(d int64) float64 {
+	if d <= 0 {
+		return 1
+	}
+	return math.Exp(-time.Duration(d).Seconds() / r.tau)
+}
+
+// WithMaxReadyRate caps the rate at which producers publish frames at
+// perSecond, for producers that generate data faster than is useful
+// downstream. Excess frames are dropped at ready time: Ready and its
+// variants do not publish a frame that exceeds the rate and count it in
+// Throttled, not in Dropped, and the hook of WithOnDrop does not see it.
+// With a single producer, the producer keeps the back buffer, as for a
+// frame rejected by WithMaxLen, so the next Back returns it again to be
+// overwritten with a newer frame; ReadyBack recycles the buffer instead.
+// A throttled Ready returns no error.
+// The cap is enforced by a lock-free token bucket on the clock of
+// WithClock that lets two frames through in quick succession after an
+// idle period, so a producer running at about perSecond with some jitter
+// is not throttled. WithMaxReadyRate has no effect if perSecond <= 0, nor
+// on a collapsed DoubleBuffer, see Collapse.
+func WithMaxReadyRate[T any](perSecond float64) Option[T] {
+	return func(db *DoubleBuffer[T]) {
+		if perSecond <= 0 {
+			db.limiter = nil
+			return
+		}
+		db.limiter = &limiter{interval: int64(float64(time.Second) / perSecond)}
+	}
+}
+
+// Throttled returns the number of frames that WithMaxReadyRate dropped
+// because they exceeded the rate.
+func (db *DoubleBuffer[T]) Throttled() uint64 { return db.throttled.Load() }
+
+// throttle reports whether the frame being readied exceeds the rate of
+// WithMaxReadyRate, counting it in that case.
+func (db *DoubleBuffer[T]) throttle() bool {
+	if db.limiter == nil || db.limiter.allow(db.sinceEpoch()) {
+		return false
+	}
+	db.throttled.Add(1)
+	return true
+}
+
+// limiter is a token bucket implemented as a generic cell rate algorithm:
+// tat is the theoretical arrival time of the next frame, and a frame is let
+// through if it arrives no more than one interval before it.
+type limiter struct {
+	interval int64        // in nanoseconds
+	tat      atomic.Int64 // in nanoseconds since the epoch
+}
+
+// allow reports whether a frame may be published at now, in nanoseconds
+// since the epoch, and takes a token if so.
+func (l *limiter) allow(now int64) bool {
+	for {
+		tat := l.tat.Load()
+		if now < tat-l.interval {
+			return false
+		}
+		if l.tat.CompareAndSwap(tat, max(tat, now)+l.interval) {
+			return true
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package doublebuf
+
+// ReadyPolicy selects which frame survives when Ready finds a readied frame
+// still pending, see WithReadyPolicy.
+type ReadyPolicy int
+
+const (
+	// LastWins replaces the pending frame with the new one and drops the
+	// pending one, so the consumer sees the freshest data. It is the
+	// default.
+	LastWins ReadyPolicy = iota
+	// FirstWins keeps the pending frame and drops the new one, preserving
+	// the oldest unhandled data.
+	FirstWins
+)
+
+// WithReadyPolicy sets which frame a Ready keeps when it finds a readied
+// frame still pending, which can only happen with extra buffers, see
+// WithBuffers. Either way the other frame is dropped, counted by Dropped,
+// and its buffer returned to the free list. Under FirstWins, Ready then
+// publishes nothing, but the producer still gives up its buffer as after a
+// successful Ready, and the next Back returns another one; TryReady returns
+// ErrFull instead, leaving the producer its frame.
+// WithMergeOnOverwrite takes precedence over the policy, and backpressure
+// mode, which waits for the pending frame to be consumed, ignores it.
+func WithReadyPolicy[T any](policy ReadyPolicy) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.readyPolicy = policy }
+}
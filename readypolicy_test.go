@@ -0,0 +1,32 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithReadyPolicy(t *testing.T) {
+	for _, tt := range []struct {
+		policy ReadyPolicy
+		want   int
+	}{
+		{LastWins, 3},
+		{FirstWins, 1},
+	} {
+		db := New(0, 0, WithBuffers(0, 0), WithReadyPolicy[int](tt.policy))
+		for i := 1; i <= 3; i++ {
+			if err := db.Publish(context.Background(), i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if v, changed := db.Next(); !changed || v != tt.want {
+			t.Fatalf("policy %d: Next = %d, %t; want %d, true", tt.policy, v, changed, tt.want)
+		}
+		if d := db.Dropped(); d != 2 {
+			t.Fatalf("policy %d: Dropped = %d, want 2", tt.policy, d)
+		}
+		if _, ok := db.TryBack(); !ok {
+			t.Fatalf("policy %d: dropped buffers not recycled", tt.policy)
+		}
+	}
+}
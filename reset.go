@@ -0,0 +1,39 @@
+package doublebuf
+
+// Reset returns the DoubleBuffer to the state New left it in, with a as the
+// back buffer and b as the front buffer, so it can be reused for a new
+// logical epoch. Any pending frame is discarded, buffers held by producers
+// are reclaimed, the history of Previous and WithHistory is cleared and the
+// generation restarts at 0. A collapsed DoubleBuffer is expanded again, see
+// Collapse. Extra buffers from WithBuffers keep their values. Reset does not
+// reopen a closed DoubleBuffer.
+// Reset must only be called while no producer or consumer is using the
+// DoubleBuffer.
+func (db *DoubleBuffer[T]) Reset(a, b T) { db.ResetGen(a, b, 0) }
+
+// ResetGen is like Reset, but restarts the generation at gen instead of 0.
+func (db *DoubleBuffer[T]) ResetGen(a, b T, gen uint64) {
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	for len(db.prev) > 0 {
+		<-db.prev
+	}
+	*db.slots[0].p, *db.slots[1].p = a, b
+	if db.multiProducer {
+		db.heldMu.Lock()
+		clear(db.held)
+		db.heldMu.Unlock()
+	}
+	db.layout()
+	db.degraded = false
+	db.counted = false
+	db.readies.Store(0)
+	db.skipped.Store(false)
+	var zero T
+	db.previous, db.hasPrevious = zero, false
+	if db.history != nil {
+		clear(db.history.ring)
+		db.history.n, db.history.head = 0, 0
+	}
+	db.gen.Store(gen)
+}
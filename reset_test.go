@@ -0,0 +1,45 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReset(t *testing.T) {
+	db := New(0, 0, WithPrevious[int]())
+	for i := 1; i <= 2; i++ {
+		back, _ := db.Back(context.Background())
+		*back = i
+		db.Ready()
+		db.Next()
+	}
+	db.Back(context.Background())
+	db.Ready()
+	db.Reset(10, 20)
+	if g := db.Generation(); g != 0 {
+		t.Fatalf("Generation after Reset = %d, want 0", g)
+	}
+	if v, changed := db.Next(); changed || v != 20 {
+		t.Fatalf("Next after Reset = %d, %t; want 20, false", v, changed)
+	}
+	if _, ok := db.Previous(); ok {
+		t.Fatal("Previous ok after Reset")
+	}
+	back, ok := db.TryBack()
+	if !ok || *back != 10 {
+		t.Fatal("Back after Reset does not return the new back buffer")
+	}
+	db.Ready()
+	db.Next()
+	if g := db.Generation(); g != 1 {
+		t.Fatalf("Generation after Reset and a swap = %d, want 1", g)
+	}
+}
+
+func TestResetGen(t *testing.T) {
+	db := New(0, 0)
+	db.ResetGen(1, 2, 7)
+	if g := db.Generation(); g != 7 {
+		t.Fatalf("Generation after ResetGen = %d, want 7", g)
+	}
+}
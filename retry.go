@@ -0,0 +1,40 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by BackRetry when every attempt timed out.
+var ErrTimeout = errors.New("doublebuf: timed out")
+
+// BackRetry is like Back, but waits for a back buffer in up to maxTries
+// attempts of at most perTry each, and returns ErrTimeout if all of them
+// time out. ctx caps the whole operation: once it is done, BackRetry returns
+// ctx.Err() without further attempts. Errors other than a per-attempt
+// timeout, such as ErrClosed, are returned immediately.
+// The timer of each attempt is released before the next one starts.
+// The concurrency rules of Back apply.
+func (db *DoubleBuffer[T]) BackRetry(ctx context.Context, perTry time.Duration, maxTries int) (*T, error) {
+	for i := 0; i < maxTries; i++ {
+		back, err := db.backAttempt(ctx, perTry)
+		if err == nil {
+			return back, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+	}
+	return nil, ErrTimeout
+}
+
+// backAttempt calls Back with a timeout of d.
+func (db *DoubleBuffer[T]) backAttempt(ctx context.Context, d time.Duration) (*T, error) {
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	return db.Back(ctx)
+}
@@ -0,0 +1,35 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackRetry(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Ready()
+	if _, err := db.BackRetry(context.Background(), time.Millisecond, 3); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("BackRetry = %v, want ErrTimeout", err)
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		db.Next()
+	}()
+	if _, err := db.BackRetry(context.Background(), time.Millisecond, 1000); err != nil {
+		t.Fatalf("BackRetry = %v, want success once the consumer swaps", err)
+	}
+}
+
+func TestBackRetryOuterContext(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Ready()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.BackRetry(ctx, time.Millisecond, 3); !errors.Is(err, context.Canceled) {
+		t.Fatalf("BackRetry = %v, want context.Canceled", err)
+	}
+}
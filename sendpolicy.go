@@ -0,0 +1,52 @@
+package doublebuf
+
+import "errors"
+
+// ErrRetireFull is reported by NextErr under SendError when a swap could not
+// hand the retired front buffer back to the free list.
+var ErrRetireFull = errors.New("doublebuf: free list full, retired buffer dropped")
+
+// SendPolicy selects what a swap does when the retired front buffer cannot
+// be handed back to the free list, see WithNextSendPolicy.
+type SendPolicy int
+
+const (
+	// SendBlock waits until the free list has room. It is the default.
+	SendBlock SendPolicy = iota
+	// SendDrop drops the retired buffer and counts it in RetiredLost.
+	SendDrop
+	// SendError drops and counts the retired buffer like SendDrop, and
+	// additionally reports ErrRetireFull from NextErr and NextResult.
+	SendError
+)
+
+// WithNextSendPolicy sets what Next does if the free list is full when a
+// swap retires the front buffer. The free list has room for every buffer
+// but the front, so this cannot happen while the DoubleBuffer is used as
+// documented, but misuse, such as readying a buffer twice, can fill it; the
+// send in Next then blocks the consumer, and holds the lock that serializes
+// swaps, until a producer takes a buffer. SendDrop and SendError make such a
+// stall visible instead: the swap completes, but the retired buffer is lost,
+// so the DoubleBuffer runs with one buffer less from then on.
+func WithNextSendPolicy[T any](policy SendPolicy) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.sendPolicy = policy }
+}
+
+// retire hands the retired front buffer old back to the free list according
+// to the send policy. db.swapMu must be held.
+func (db *DoubleBuffer[T]) retire(old *slot[T]) error {
+	if db.sendPolicy == SendBlock {
+		db.prev <- old
+		return nil
+	}
+	select {
+	case db.prev <- old:
+		return nil
+	default:
+	}
+	db.lost.Add(1)
+	if db.sendPolicy == SendError {
+		return ErrRetireFull
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithNextSendPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		policy SendPolicy
+		err    error
+	}{
+		{SendDrop, nil},
+		{SendError, ErrRetireFull},
+	} {
+		db := New(0, 0, WithNextSendPolicy[int](tc.policy))
+		db.Publish(context.Background(), 1)
+		// Fill the free list behind the DoubleBuffer's back, as misuse would.
+		db.prev <- &slot[int]{p: new(int)}
+		v, changed, err := db.NextErr()
+		if v != 1 || !changed || !errors.Is(err, tc.err) {
+			t.Fatalf("policy %d: NextErr = %d, %t, %v; want 1, true, %v", tc.policy, v, changed, err, tc.err)
+		}
+		if got := db.RetiredLost(); got != 1 {
+			t.Fatalf("policy %d: RetiredLost = %d, want 1", tc.policy, got)
+		}
+	}
+}
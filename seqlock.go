@@ -0,0 +1,34 @@
+package doublebuf
+
+import "runtime"
+
+// FrontSeqlock returns a consistent copy of the front value even when it is
+// read concurrently with swaps, for a large T that many goroutines read.
+// Front only loads the pointer to the front buffer atomically; copying a
+// large value through it can overlap a swap followed by the producer
+// rewriting the retired buffer, and yield a mix of two frames.
+// FrontSeqlock guards the copy with a sequence number that swaps, and other
+// writers of the front such as CompareAndSwapFront, make odd while they
+// replace the front and bump again once done: a copy is retried if the
+// sequence was odd or changed while it was taken. Writers never wait for
+// readers, and readers only retry while swaps keep happening during their
+// copy, so with a producer that is slow relative to the size of T,
+// FrontSeqlock rarely takes more than one pass.
+// The copy that is discarded on a retry still races with the writer, so the
+// race detector may report it.
+func (db *DoubleBuffer[T]) FrontSeqlock() T { return db.copyOut(db.frontSeqlock()) }
+
+// frontSeqlock implements FrontSeqlock, without WithCopyOnFront.
+func (db *DoubleBuffer[T]) frontSeqlock() T {
+	for {
+		seq := db.seq.Load()
+		if seq&1 != 0 { // a swap is in progress
+			runtime.Gosched()
+			continue
+		}
+		t := *db.loadFront().p
+		if db.seq.Load() == seq {
+			return t
+		}
+	}
+}
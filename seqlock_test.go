@@ -0,0 +1,60 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFrontSeqlock(t *testing.T) {
+	db := New(0, 1)
+	if v := db.FrontSeqlock(); v != 1 {
+		t.Fatalf("FrontSeqlock = %d, want 1", v)
+	}
+	db.Publish(context.Background(), 2)
+	db.Next()
+	if v := db.FrontSeqlock(); v != 2 {
+		t.Fatalf("FrontSeqlock after a swap = %d, want 2", v)
+	}
+	if s := db.seq.Load(); s&1 != 0 {
+		t.Fatalf("sequence %d odd after a swap", s)
+	}
+}
+
+// BenchmarkFrontRead compares plain and seqlock-guarded reads of a large
+// front value by parallel readers while a producer keeps swapping.
+func BenchmarkFrontRead(b *testing.B) {
+	type large [64]int64
+	for _, bc := range []struct {
+		name string
+		read func(*DoubleBuffer[large]) large
+	}{
+		{"Front", (*DoubleBuffer[large]).Front},
+		{"Seqlock", (*DoubleBuffer[large]).FrontSeqlock},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			db := New(large{}, large{})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				for i := int64(0); ; i++ {
+					back, err := db.Back(ctx)
+					if err != nil {
+						return
+					}
+					for j := range back {
+						back[j] = i
+					}
+					db.Ready()
+					db.Next()
+				}
+			}()
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_ = bc.read(db)
+				}
+			})
+		})
+	}
+}
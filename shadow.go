@@ -0,0 +1,40 @@
+package doublebuf
+
+import "context"
+
+// Shadow returns a scratch buffer the producer can build its next frame in
+// while it has no back buffer, e.g. right after readying a frame that the
+// consumer has not swapped in yet, when Back would block. Once the frame is
+// complete, ReadyShadow publishes it. Shadow returns the same buffer until
+// then.
+// The shadow buffer is one more T, allocated by the first call to Shadow,
+// so it costs the memory of a third buffer, but unlike WithBuffers it never
+// carries a frame the consumer sees, and frames are not coalesced. It starts
+// out as the zero value of T.
+// The concurrency rules of Back apply.
+func (db *DoubleBuffer[T]) Shadow() *T {
+	db.checkSingleProducer("Shadow")
+	if db.shadow == nil {
+		db.shadow = new(T)
+	}
+	return db.shadow
+}
+
+// ReadyShadow publishes the frame built in the shadow buffer: it waits for a
+// back buffer as Back does, exchanges the contents of the two buffers and
+// readies the back buffer, as ReadyContext does. Nothing is copied beyond
+// the values of T themselves, so for a slice or pointer type the frame's
+// backing memory moves to the consumer, and the shadow buffer afterwards holds
+// what the back buffer held before, ready to be reused for the next frame.
+// ReadyShadow returns the error of Back or ReadyContext, if any; if Back
+// fails, the shadow buffer keeps the frame.
+// The concurrency rules of Back and Ready apply.
+func (db *DoubleBuffer[T]) ReadyShadow(ctx context.Context) error {
+	shadow := db.Shadow()
+	back, err := db.Back(ctx)
+	if err != nil {
+		return err
+	}
+	*back, *shadow = *shadow, *back
+	return db.ReadyContext(ctx)
+}
@@ -0,0 +1,27 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestShadow(t *testing.T) {
+	db := New([]int(nil), []int(nil))
+	db.Publish(context.Background(), []int{1})
+	if !db.BackWouldBlock() {
+		t.Fatal("Back would not block with a frame pending")
+	}
+	shadow := db.Shadow()
+	*shadow = append((*shadow)[:0], 2)
+	db.Next()
+	if err := db.ReadyShadow(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, changed := db.Next(); !changed || len(v) != 1 || v[0] != 2 {
+		t.Fatalf("Next = %v, %t; want [2], true", v, changed)
+	}
+	// The shadow now holds the storage the back buffer had, not the frame.
+	if s := *db.Shadow(); len(s) == 1 && s[0] == 2 {
+		t.Fatal("shadow buffer still aliases the published frame")
+	}
+}
@@ -0,0 +1,54 @@
+package doublebuf
+
+// BufferSource supplies the backing buffers of a DoubleBuffer created with
+// NewFromSource. It allows many DoubleBuffers to share a free list, for
+// example a size-bucketed pool of byte slices.
+//
+// The lifecycle of a sourced DoubleBuffer is:
+//
+//  1. NewFromSource calls Acquire exactly twice, once for each backing
+//     buffer.
+//  2. The buffers are used as with New.
+//  3. When the DoubleBuffer is closed, by Close or by a CloseDrain that
+//     returns nil, Release is called exactly once for each backing buffer
+//     with its current value. A buffer that has been reassigned through the
+//     pointer returned by Back (e.g. a slice that grew) is released with its
+//     new value, not with the value originally acquired. The automatic
+//     close of BindContext does not release the buffers; a later Close or
+//     CloseDrain does.
+//
+// After Release the values must no longer be used, so callers must stop
+// producing and consuming before closing a sourced DoubleBuffer; this
+// includes values previously returned by Front and Next.
+// A CloseDrain that returns an error does not release the buffers.
+type BufferSource[T any] interface {
+	// Acquire returns a buffer for exclusive use by a DoubleBuffer.
+	Acquire() T
+	// Release returns a buffer previously obtained from Acquire.
+	Release(T)
+}
+
+// NewFromSource creates a DoubleBuffer whose backing buffers are acquired
+// from src and released back to it when the DoubleBuffer is closed.
+// See BufferSource for the exact lifecycle.
+// NewFromSource panics if src is nil.
+func NewFromSource[T any](src BufferSource[T]) *DoubleBuffer[T] {
+	if src == nil {
+		panic("doublebuf: NewFromSource called with a nil BufferSource")
+	}
+	db := New(src.Acquire(), src.Acquire())
+	db.src = src
+	return db
+}
+
+// release returns the backing buffers to their source, if any.
+func (db *DoubleBuffer[T]) release() {
+	if db.src == nil {
+		return
+	}
+	db.releaseOnce.Do(func() {
+		for _, s := range db.slots[:2] {
+			db.invoke("Release", func() { db.src.Release(*s.p) })
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type countingSource struct {
+	acquired int
+	released []int
+}
+
+func (s *countingSource) Acquire() int {
+	s.acquired++
+	return s.acquired
+}
+
+func (s *countingSource) Release(v int) { s.released = append(s.released, v) }
+
+func TestNewFromSource(t *testing.T) {
+	src := &countingSource{}
+	db := NewFromSource[int](src)
+	if src.acquired != 2 {
+		t.Fatalf("acquired %d buffers, want 2", src.acquired)
+	}
+	back, _ := db.Back(context.Background())
+	*back = 10
+	db.Close()
+	db.Close()
+	if len(src.released) != 2 {
+		t.Fatalf("released %v, want 2 buffers", src.released)
+	}
+	if src.released[0] != 10 || src.released[1] != 2 {
+		t.Fatalf("released %v, want [10 2]", src.released)
+	}
+}
+
+func TestNewFromSourceBindContext(t *testing.T) {
+	src := &countingSource{}
+	db := NewFromSource[int](src)
+	ctx, cancel := context.WithCancel(context.Background())
+	db.BindContext(ctx)
+	cancel()
+	<-db.done
+	if _, err := db.Back(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Fatalf("Back after the context was cancelled = %v, want ErrClosed", err)
+	}
+	if len(src.released) != 0 {
+		t.Fatalf("the context released %v while the buffers may be in use", src.released)
+	}
+	db.Close()
+	if len(src.released) != 2 {
+		t.Fatalf("Close released %v, want 2 buffers", src.released)
+	}
+}
@@ -0,0 +1,121 @@
+package doublebuf
+
+import "sync/atomic"
+
+// SPMCBuffer is a latest-value broadcast from a single producer to any
+// number of consumers, each reading independently through its own cursor,
+// see AddConsumer. Unlike a DoubleBuffer, there is no swap: Ready makes the
+// frame visible to every consumer at once, and consumers never block the
+// producer. A consumer always reads the latest published frame and skips
+// whatever was published since its previous read, so a slow consumer
+// misses frames rather than holding anybody up.
+//
+// To let readers copy a frame while the producer moves on, the producer
+// never writes to a buffer that is published or being read: Back picks a
+// buffer that is neither, and allocates a new one if all are busy. The
+// number of buffers is therefore bounded by the number of reads in
+// progress at a time, plus two.
+type SPMCBuffer[T any] struct {
+	latest atomic.Pointer[spmcSlot[T]]
+	bufs   []*spmcSlot[T] // producer only
+	back   *spmcSlot[T]   // producer only, nil after Ready
+	seq    uint64         // producer only, number of frames published
+}
+
+type spmcSlot[T any] struct {
+	v       T
+	seq     uint64       // written before the slot is published
+	readers atomic.Int32 // reads in progress
+}
+
+// NewSPMC creates an SPMCBuffer with a as the initial back buffer and b as
+// the initial latest frame.
+func NewSPMC[T any](a, b T) *SPMCBuffer[T] {
+	back, front := &spmcSlot[T]{v: a}, &spmcSlot[T]{v: b}
+	s := &SPMCBuffer[T]{bufs: []*spmcSlot[T]{back, front}, back: back}
+	s.latest.Store(front)
+	return s
+}
+
+// Back returns the back buffer, which the producer fills with the next
+// frame. Back returns the same buffer until Ready is called. After Ready,
+// the next Back returns a recycled buffer that still holds an older frame,
+// or a zero value when a new buffer had to be allocated, so the producer
+// must overwrite it. Back never blocks.
+// Back and Ready must only be called by one producer goroutine.
+func (s *SPMCBuffer[T]) Back() *T {
+	if s.back == nil {
+		s.back = s.free()
+	}
+	return &s.back.v
+}
+
+// free returns a buffer that is neither published nor being read.
+func (s *SPMCBuffer[T]) free() *spmcSlot[T] {
+	latest := s.latest.Load()
+	for _, b := range s.bufs {
+		// A reader that increments b.readers after this check finds b no
+		// longer published and backs off without reading.
+		if b != latest && b.readers.Load() == 0 {
+			return b
+		}
+	}
+	b := new(spmcSlot[T])
+	s.bufs = append(s.bufs, b)
+	return b
+}
+
+// Ready publishes the back buffer as the latest frame, replacing the
+// previous one for every consumer, and is a no-op if the producer holds no
+// back buffer.
+func (s *SPMCBuffer[T]) Ready() {
+	if s.back == nil {
+		return
+	}
+	s.seq++
+	s.back.seq = s.seq
+	s.latest.Store(s.back)
+	s.back = nil
+}
+
+// Buffers returns the number of buffers allocated so far.
+// It follows the concurrency rules of Back.
+func (s *SPMCBuffer[T]) Buffers() int { return len(s.bufs) }
+
+// SPMCConsumer is a read cursor of an SPMCBuffer, see AddConsumer.
+type SPMCConsumer[T any] struct {
+	s    *SPMCBuffer[T]
+	seen uint64
+}
+
+// AddConsumer returns a new read cursor. Each consumer goroutine needs its
+// own: the cursor remembers the last frame it returned, so Next reports
+// frames that are new to this consumer, regardless of the others. The
+// cursor starts out having seen the initial frame. Consumers need no
+// unregistering: a cursor that is no longer used costs nothing.
+// AddConsumer is safe to call concurrently with all other methods.
+func (s *SPMCBuffer[T]) AddConsumer() *SPMCConsumer[T] {
+	return &SPMCConsumer[T]{s: s}
+}
+
+// Next returns a copy of the latest published frame, and whether it is newer
+// than the one this cursor returned last, in which case any frames
+// published in between were skipped. Next never blocks the producer, and
+// only retries its read while the producer keeps publishing during it.
+// Next is safe to call concurrently with the producer and with other
+// cursors, but a cursor must not be used by several goroutines at once.
+func (c *SPMCConsumer[T]) Next() (t T, changed bool) {
+	for {
+		b := c.s.latest.Load()
+		b.readers.Add(1)
+		if c.s.latest.Load() != b { // replaced, and maybe already reused
+			b.readers.Add(-1)
+			continue
+		}
+		t, seq := b.v, b.seq
+		b.readers.Add(-1)
+		changed = seq != c.seen
+		c.seen = seq
+		return t, changed
+	}
+}
@@ -0,0 +1,98 @@
+package doublebuf
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSPMCBuffer(t *testing.T) {
+	s := NewSPMC(0, 0)
+	c1, c2 := s.AddConsumer(), s.AddConsumer()
+	if _, changed := c1.Next(); changed {
+		t.Fatal("Next before the first Ready reported a change")
+	}
+	for i := 1; i <= 3; i++ {
+		*s.Back() = i
+		s.Ready()
+		if v, changed := c1.Next(); !changed || v != i {
+			t.Fatalf("c1.Next = %d, %t; want %d, true", v, changed, i)
+		}
+	}
+	if _, changed := c1.Next(); changed {
+		t.Fatal("Next reported a change twice for the same frame")
+	}
+	if v, changed := c2.Next(); !changed || v != 3 {
+		t.Fatalf("c2.Next = %d, %t; want the latest frame 3, true", v, changed)
+	}
+	if n := s.Buffers(); n != 2 {
+		t.Fatalf("Buffers = %d with no concurrent reads, want 2", n)
+	}
+}
+
+func TestSPMCBufferConcurrent(t *testing.T) {
+	type frame struct{ a, b int }
+	s := NewSPMC(frame{}, frame{})
+	const frames, consumers = 10000, 4
+	var wg sync.WaitGroup
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c := s.AddConsumer()
+			last := 0
+			for last < frames {
+				v, changed := c.Next()
+				if v.a != v.b {
+					t.Errorf("torn frame %+v", v)
+					return
+				}
+				if changed && v.a <= last {
+					t.Errorf("frame %d after %d", v.a, last)
+					return
+				}
+				if !changed {
+					runtime.Gosched()
+				}
+				last = v.a
+			}
+		}()
+	}
+	for i := 1; i <= frames; i++ {
+		back := s.Back()
+		back.a, back.b = i, i
+		s.Ready()
+		if i%64 == 0 {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+}
+
+func BenchmarkSPMCBuffer(b *testing.B) {
+	s := NewSPMC(0, 0)
+	done := make(chan struct{})
+	// feeder goroutine
+	go func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			*s.Back() = i
+			s.Ready()
+			runtime.Gosched()
+		}
+	}()
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		c := s.AddConsumer()
+		for pb.Next() {
+			c.Next()
+		}
+	})
+	b.StopTimer()
+	close(done)
+}
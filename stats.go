@@ -0,0 +1,120 @@
+package doublebuf
+
+import "time"
+
+// The accessors below are plain atomic reads, cheap and safe to call
+// concurrently with all other methods, so they can be wired into any
+// metrics system without this package depending on one.
+
+// SwapCount returns the number of swaps performed by Next.
+func (db *DoubleBuffer[T]) SwapCount() uint64 { return db.swaps.Load() }
+
+// IdleNext returns the number of calls to Next that found nothing to swap.
+// Together with SwapCount it gives the ratio of idle consumer iterations.
+func (db *DoubleBuffer[T]) IdleNext() uint64 { return db.idle.Load() }
+
+// Dropped returns the number of readied frames that were replaced by a newer
+// frame before being swapped in. Frames can only be dropped with WithBuffers.
+func (db *DoubleBuffer[T]) Dropped() uint64 { return db.dropped.Load() }
+
+// InFlight returns the number of back buffers currently held by producers,
+// i.e. handed out by Back or AcquireBack and not readied yet.
+func (db *DoubleBuffer[T]) InFlight() int { return int(db.inFlight.Load()) }
+
+// Cap returns the number of backing buffers, 2 plus the extra buffers of
+// WithBuffers, or the current number with WithAdaptiveDepth, in which case
+// Cap briefly takes the lock that guards the buffers.
+func (db *DoubleBuffer[T]) Cap() int {
+	if db.adapt != nil {
+		db.slotsMu.Lock()
+		defer db.slotsMu.Unlock()
+	}
+	return len(db.slots)
+}
+
+// Depth returns the number of readied frames waiting to be swapped in. Since
+// a newer frame replaces a pending one rather than queueing behind it, Depth
+// is always 0 or 1, whatever Cap is; the other buffers are the front, those
+// held by producers, see InFlight, and free ones.
+func (db *DoubleBuffer[T]) Depth() int {
+	if db.pending() {
+		return 1
+	}
+	return 0
+}
+
+// BackWaitTotal returns the cumulative time producers have spent blocked in
+// Back waiting for the consumer to hand back a buffer.
+// Calls to Back that find a buffer immediately do not contribute.
+// A high value means the consumer is the bottleneck, a low value means the
+// producer is.
+func (db *DoubleBuffer[T]) BackWaitTotal() time.Duration {
+	return time.Duration(db.backWait.Load())
+}
+
+// BackCount returns the number of frames producers started: calls to Back,
+// TryBack or AcquireBack that handed out a buffer for a new frame. Repeated
+// calls to Back for the same frame count once.
+func (db *DoubleBuffer[T]) BackCount() uint64 { return db.backs.Load() }
+
+// ReadyCount returns the number of frames producers published with Ready or
+// ReadyBack. The difference between BackCount and ReadyCount is the number
+// of frames that were abandoned, rejected or throttled instead of
+// published, plus those still being filled.
+func (db *DoubleBuffer[T]) ReadyCount() uint64 { return db.readys.Load() }
+
+// Filtered returns the number of readied frames that the predicate of
+// WithFrontFilter recycled instead of swapping in.
+func (db *DoubleBuffer[T]) Filtered() uint64 { return db.filtered.Load() }
+
+// BackCancelled returns the number of times a producer waiting in Back or
+// AcquireBack for a free buffer gave up because its context was done, as
+// opposed to getting a buffer or seeing the DoubleBuffer closed.
+func (db *DoubleBuffer[T]) BackCancelled() uint64 { return db.cancelled.Load() }
+
+// OutOfOrder returns the number of readied frames that WithSequence
+// recycled because they were not newer than the front.
+func (db *DoubleBuffer[T]) OutOfOrder() uint64 { return db.outOfOrder.Load() }
+
+// RetiredLost returns the number of buffers retired by a swap that were
+// dropped because the free list was full, see WithNextSendPolicy.
+func (db *DoubleBuffer[T]) RetiredLost() uint64 { return db.lost.Load() }
+
+// Rejected returns the number of frames rejected on Ready by WithMaxLen.
+func (db *DoubleBuffer[T]) Rejected() uint64 { return db.rejected.Load() }
+
+// LastSwap returns the time of the most recent swap, or the time the
+// DoubleBuffer was created if it has not swapped yet.
+func (db *DoubleBuffer[T]) LastSwap() time.Time {
+	return db.epoch.Add(time.Duration(db.lastSwap.Load()))
+}
+
+// StalledFor reports whether no swap has happened for at least d, which
+// lets a polling consumer tell a slow producer from an absent one, and back
+// off or raise an alarm accordingly.
+func (db *DoubleBuffer[T]) StalledFor(d time.Duration) bool {
+	return db.now().Sub(db.LastSwap()) >= d
+}
+
+// ResetStats zeroes the cumulative counters SwapCount, IdleNext, Dropped,
+// Filtered, OutOfOrder, Rejected, Throttled, RetiredLost, BackCount,
+// ReadyCount, BackCancelled and BackWaitTotal, e.g. at the boundaries of a
+// reporting interval.
+// Gauges such as InFlight, the generation and LastSwap are not affected, and
+// neither is the data itself. ResetStats is safe to call concurrently with
+// normal operation; the counters are zeroed one at a time, so a concurrent
+// reader may briefly see some of them reset and others not.
+func (db *DoubleBuffer[T]) ResetStats() {
+	db.swaps.Store(0)
+	db.idle.Store(0)
+	db.dropped.Store(0)
+	db.filtered.Store(0)
+	db.outOfOrder.Store(0)
+	db.rejected.Store(0)
+	db.throttled.Store(0)
+	db.lost.Store(0)
+	db.backs.Store(0)
+	db.readys.Store(0)
+	db.cancelled.Store(0)
+	db.backWait.Store(0)
+}
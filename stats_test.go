@@ -0,0 +1,266 @@
+package doublebuf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"maps"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBackWaitTotal(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	if got := db.BackWaitTotal(); got != 0 {
+		t.Fatalf("BackWaitTotal after fast path = %v, want 0", got)
+	}
+	db.Ready()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Next()
+	}()
+	if _, err := db.Back(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := db.BackWaitTotal(); got < 10*time.Millisecond {
+		t.Fatalf("BackWaitTotal = %v, want at least 10ms", got)
+	}
+}
+
+func TestBackTimed(t *testing.T) {
+	db := New(0, 0)
+	if _, d, err := db.BackTimed(context.Background()); err != nil || d != 0 {
+		t.Fatalf("BackTimed on the fast path = %v, %v; want 0, nil", d, err)
+	}
+	db.Ready()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Next()
+	}()
+	_, d, err := db.BackTimed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d < 10*time.Millisecond || d > db.BackWaitTotal() {
+		t.Fatalf("BackTimed waited %v, want at least 10ms and at most BackWaitTotal %v", d, db.BackWaitTotal())
+	}
+}
+
+func TestStalledFor(t *testing.T) {
+	db := New(0, 0)
+	if db.StalledFor(time.Hour) {
+		t.Fatal("StalledFor(time.Hour) right after New")
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !db.StalledFor(10 * time.Millisecond) {
+		t.Fatal("StalledFor(10ms) = false after sleeping 10ms")
+	}
+	before := db.LastSwap()
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	if !db.LastSwap().After(before) {
+		t.Fatal("LastSwap did not advance on swap")
+	}
+	if db.StalledFor(10 * time.Millisecond) {
+		t.Fatal("StalledFor(10ms) right after a swap")
+	}
+}
+
+func TestCounters(t *testing.T) {
+	db := New(0, 0, WithBuffers(0))
+	if got := db.InFlight(); got != 1 {
+		t.Fatalf("InFlight after New = %d, want 1", got)
+	}
+	for i := 0; i < 2; i++ {
+		db.TryBack()
+		db.Ready()
+	}
+	if got := db.InFlight(); got != 0 {
+		t.Fatalf("InFlight after Ready = %d, want 0", got)
+	}
+	db.Next()
+	db.Next()
+	if got := db.SwapCount(); got != 1 {
+		t.Fatalf("SwapCount = %d, want 1", got)
+	}
+	if got := db.IdleNext(); got != 1 {
+		t.Fatalf("IdleNext = %d, want 1", got)
+	}
+	if got := db.Dropped(); got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+	db.ResetStats()
+	if db.SwapCount() != 0 || db.IdleNext() != 0 || db.Dropped() != 0 {
+		t.Fatal("counters not zero after ResetStats")
+	}
+	if got := db.Generation(); got != 1 {
+		t.Fatalf("Generation after ResetStats = %d, want 1", got)
+	}
+}
+
+// fakeClock is a manually advanced clock for WithClock.
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func TestWithClock(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now))
+	clock.advance(time.Minute)
+	if !db.StalledFor(time.Minute) {
+		t.Fatal("StalledFor(time.Minute) = false after advancing a minute")
+	}
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	if got, want := db.LastSwap(), time.Unix(60, 0); !got.Equal(want) {
+		t.Fatalf("LastSwap = %v, want %v", got, want)
+	}
+	if db.StalledFor(time.Nanosecond) {
+		t.Fatal("StalledFor(1ns) right after a swap with a frozen clock")
+	}
+}
+
+func TestBackReadyCount(t *testing.T) {
+	db := New(0, 0)
+	db.Back(context.Background())
+	db.Back(context.Background())
+	db.Ready()
+	db.Next()
+	db.Back(context.Background())
+	db.Abandon()
+	db.Back(context.Background())
+	if b, r := db.BackCount(), db.ReadyCount(); b != 3 || r != 1 {
+		t.Fatalf("BackCount, ReadyCount = %d, %d; want 3, 1", b, r)
+	}
+	db.ResetStats()
+	if b, r := db.BackCount(), db.ReadyCount(); b != 0 || r != 0 {
+		t.Fatalf("BackCount, ReadyCount after ResetStats = %d, %d; want 0, 0", b, r)
+	}
+}
+
+func TestCapDepth(t *testing.T) {
+	db := New(0, 0, WithBuffers(0))
+	if c, d := db.Cap(), db.Depth(); c != 3 || d != 0 {
+		t.Fatalf("Cap, Depth = %d, %d; want 3, 0", c, d)
+	}
+	db.Publish(context.Background(), 1)
+	db.Publish(context.Background(), 2)
+	if d := db.Depth(); d != 1 {
+		t.Fatalf("Depth with a pending frame = %d, want 1", d)
+	}
+	db.Next()
+	if d := db.Depth(); d != 0 {
+		t.Fatalf("Depth after Next = %d, want 0", d)
+	}
+}
+
+func TestBackCancelled(t *testing.T) {
+	var hooked error
+	db := New(0, 0, WithOnBackCancel[int](func(err error) { hooked = err }))
+	db.Publish(context.Background(), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := db.Back(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Back = %v, want Canceled", err)
+	}
+	if got := db.BackCancelled(); got != 1 {
+		t.Fatalf("BackCancelled = %d, want 1", got)
+	}
+	if !errors.Is(hooked, context.Canceled) {
+		t.Fatalf("WithOnBackCancel hook got %v, want Canceled", hooked)
+	}
+}
+
+func TestWithRates(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now), WithRates[int](time.Second))
+	for i := 0; i < 100; i++ { // 10 frames per second for 10 windows
+		clock.advance(100 * time.Millisecond)
+		db.Publish(context.Background(), i)
+		db.Next()
+	}
+	for name, rate := range map[string]func() float64{"ProduceRate": db.ProduceRate, "ConsumeRate": db.ConsumeRate} {
+		if got := rate(); got < 9.5 || got > 11 {
+			t.Fatalf("%s = %g, want about 10", name, got)
+		}
+	}
+	before := db.ProduceRate()
+	clock.advance(time.Second)
+	if got, want := db.ProduceRate(), before*math.Exp(-1); math.Abs(got-want) > 1e-9 {
+		t.Fatalf("ProduceRate after an idle window = %g, want %g", got, want)
+	}
+	if got := New(0, 0).ProduceRate(); got != 0 {
+		t.Fatalf("ProduceRate without WithRates = %g, want 0", got)
+	}
+}
+
+func TestWithExpvar(t *testing.T) {
+	name := fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano()) // unique across -count
+	db := New(0, 0, WithBuffers(1), WithExpvar[int](name))
+	db.Publish(context.Background(), 1)
+	db.Publish(context.Background(), 2) // drops 1
+	db.Next()
+	db.Publish(context.Background(), 3)
+	var got map[string]int
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"swaps": 1, "dropped": 1, "depth": 1}; !maps.Equal(got, want) {
+		t.Fatalf("expvar %s = %v, want %v", name, got, want)
+	}
+}
+
+func TestWithMaxReadyRate(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	db := New(0, 0, WithClock[int](clock.now), WithMaxReadyRate[int](10))
+	publish := func(v int) {
+		back, _ := db.Back(context.Background())
+		*back = v
+		db.Ready()
+	}
+	publish(1)
+	db.Next()
+	publish(2) // the burst of two after idling
+	db.Next()
+	publish(3) // throttled, the producer keeps the buffer
+	if v, changed := db.Next(); changed {
+		t.Fatalf("Next after a throttled Ready = %d, true; want no change", v)
+	}
+	clock.advance(100 * time.Millisecond)
+	publish(4)
+	if v, changed := db.Next(); !changed || v != 4 {
+		t.Fatalf("Next = %d, %t; want 4, true", v, changed)
+	}
+	if got := db.Throttled(); got != 1 {
+		t.Fatalf("Throttled = %d, want 1", got)
+	}
+	clock.advance(time.Second) // idle, so the burst is available again
+	for i := 0; i < 100; i++ { // at the cap, with jitter
+		clock.advance(time.Duration(50+i%2*100) * time.Millisecond)
+		publish(i)
+		db.Next()
+	}
+	if got := db.Throttled(); got != 1 {
+		t.Fatalf("Throttled at the cap = %d, want 1", got)
+	}
+}
@@ -0,0 +1,165 @@
+package doublebuf
+
+import (
+	"context"
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+// stressFrames is the number of frames each stress run publishes.
+func stressFrames(t *testing.T) int {
+	if testing.Short() {
+		return 1000
+	}
+	return 10000
+}
+
+// TestStress interleaves a producer and a consumer at random and checks the
+// invariants of the handoff. Run it with -race to check the synchronization
+// as well.
+func TestStress(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []Option[int]
+		lossless bool
+	}{
+		{"Double", nil, true},
+		{"Triple", []Option[int]{WithBuffers(0)}, false},
+		{"Quad", []Option[int]{WithBuffers(0, 0)}, false},
+		{"Backpressure", []Option[int]{WithBuffers(0), WithBackpressure[int]()}, true},
+		{"Adaptive", []Option[int]{WithAdaptiveDepth(2, 4, func() int { return 0 })}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stress(t, New(0, 0, tc.opts...), stressFrames(t), tc.lossless)
+		})
+	}
+}
+
+// stress publishes frames 1 to n and consumes them concurrently, calling
+// Back, Ready, Next and Front in random order. Frames must arrive in order,
+// without gaps if lossless is set, and the counters must add up.
+func stress(t *testing.T, db *DoubleBuffer[int], n int, lossless bool) {
+	t.Helper()
+	ctx := context.Background()
+	errc := make(chan string, 1)
+	fail := func(msg string) {
+		select {
+		case errc <- msg:
+		default:
+		}
+	}
+	go func() {
+		defer db.CloseDrain(ctx)
+		r := rand.New(rand.NewSource(1))
+		for i := 1; i <= n; i++ {
+			for k := r.Intn(3); k >= 0; k-- {
+				back, err := db.Back(ctx)
+				if err != nil {
+					fail("Back: " + err.Error())
+					return
+				}
+				if back == db.front.Load().p {
+					fail("back buffer aliases the front buffer")
+				}
+				*back = i
+			}
+			db.Ready()
+		}
+	}()
+
+	r := rand.New(rand.NewSource(2))
+	last, swaps := 0, uint64(0)
+	for {
+		var v int
+		var changed bool
+		var err error
+		if r.Intn(4) == 0 {
+			v = db.Front()
+			if v != last {
+				t.Fatalf("Front = %d, want %d", v, last)
+			}
+			continue
+		}
+		v, changed, err = db.NextErr()
+		if err != nil {
+			break
+		}
+		if !changed {
+			runtime.Gosched() // let the producer run on a single CPU
+			continue
+		}
+		swaps++
+		if v <= last || lossless && v != last+1 {
+			t.Fatalf("swapped in frame %d after frame %d", v, last)
+		}
+		last = v
+	}
+	select {
+	case msg := <-errc:
+		t.Fatal(msg)
+	default:
+	}
+	if last != n {
+		t.Fatalf("last frame = %d, want %d", last, n)
+	}
+	if got := db.SwapCount(); got != swaps {
+		t.Fatalf("SwapCount = %d, want %d", got, swaps)
+	}
+	if got, want := db.ReadyCount(), db.SwapCount()+db.Dropped(); got != want {
+		t.Fatalf("ReadyCount = %d, want SwapCount+Dropped = %d", got, want)
+	}
+	if lossless && db.Dropped() != 0 {
+		t.Fatalf("Dropped = %d in lossless mode", db.Dropped())
+	}
+}
+
+// FuzzOps drives a DoubleBuffer with a sequence of operations decoded from
+// the input on a single goroutine, checking the same invariants as
+// TestStress after every step.
+func FuzzOps(f *testing.F) {
+	f.Add([]byte{0, 1, 2, 0, 1, 2})
+	f.Add([]byte{0, 1, 0, 1, 0, 1, 2, 3})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		db := New(0, 0, WithBuffers(0))
+		next, last := 1, 0
+		held := false
+		for _, op := range ops {
+			switch op % 4 {
+			case 0:
+				back, ok := db.TryBack()
+				if !ok {
+					continue
+				}
+				if back == db.front.Load().p {
+					t.Fatal("back buffer aliases the front buffer")
+				}
+				*back = next
+				held = true
+			case 1:
+				if held {
+					db.Ready()
+					next++
+					held = false
+				}
+			case 2:
+				v, changed := db.Next()
+				if changed && v <= last {
+					t.Fatalf("swapped in frame %d after frame %d", v, last)
+				}
+				last = v
+			case 3:
+				if v := db.Front(); v != last {
+					t.Fatalf("Front = %d, want %d", v, last)
+				}
+			}
+			pending := uint64(0)
+			if db.pending() {
+				pending = 1
+			}
+			if got, want := db.ReadyCount(), db.SwapCount()+db.Dropped()+pending; got != want {
+				t.Fatalf("ReadyCount = %d, want %d", got, want)
+			}
+		}
+	})
+}
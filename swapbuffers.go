@@ -0,0 +1,53 @@
+package doublebuf
+
+// SwapBuffers replaces the storage of the two buffers passed to the
+// constructor with a and b, e.g. to resize them, and returns their previous
+// values, old for the buffer that started out as the back buffer and old2
+// for the one that started out as the front buffer. Unlike Reset it does not
+// need the pipeline to stop: whichever role each buffer has at the time of
+// the call, it keeps with its new storage. If one of them is the front
+// buffer, Front switches to the new value atomically and the generation is
+// bumped, so readers see either the old or the new value, never a mix; a
+// pending frame or the frame being filled in one of them is replaced along
+// with it. Extra buffers from WithBuffers are left alone.
+// SwapBuffers holds the lock that serializes swaps while it runs, so a
+// concurrent Next waits for it briefly. It must be called by the producer,
+// between frames: a pointer previously returned by Back must not be used
+// afterwards, call Back again instead. The new storage is allocated by
+// SwapBuffers, so caller-owned storage passed to NewPtr is no longer used.
+// SwapBuffers is safe to call concurrently with Next and Front. It panics on
+// a multi-producer or collapsed DoubleBuffer, see Collapse.
+func (db *DoubleBuffer[T]) SwapBuffers(a, b T) (old T, old2 T) {
+	db.checkSingleProducer("SwapBuffers")
+	if db.degraded {
+		panic("doublebuf: SwapBuffers called on a collapsed DoubleBuffer")
+	}
+	db.swapMu.Lock()
+	defer db.swapMu.Unlock()
+	old = db.replaceSlot(0, a)
+	old2 = db.replaceSlot(1, b)
+	return old, old2
+}
+
+// replaceSlot moves slots[i] to new storage holding v and returns its old
+// value; db.swapMu must be held.
+func (db *DoubleBuffer[T]) replaceSlot(i int, v T) T {
+	s := db.slots[i]
+	p := new(T)
+	*p = v
+	if db.front.Load() != s {
+		// Only the producer, which is calling, and swaps, which are
+		// locked out, touch a buffer that is not the front.
+		old := *s.p
+		s.p = p
+		return old
+	}
+	// Readers may be dereferencing the front, so publish a new slot instead.
+	ns := &slot[T]{p: p, id: s.id, readyAt: s.readyAt, data: s.data, dirty: s.dirty}
+	db.slots[i] = ns
+	db.seq.Add(1)
+	db.front.Store(ns)
+	db.gen.Add(1)
+	db.seq.Add(1)
+	return *s.p
+}
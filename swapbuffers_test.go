@@ -0,0 +1,29 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSwapBuffers(t *testing.T) {
+	db := New([]int{1}, []int{2})
+	old, old2 := db.SwapBuffers(make([]int, 0, 8), []int{3, 4})
+	if len(old) != 1 || old[0] != 1 || len(old2) != 1 || old2[0] != 2 {
+		t.Fatalf("SwapBuffers returned %v, %v; want [1], [2]", old, old2)
+	}
+	if g := db.Generation(); g != 1 {
+		t.Fatalf("Generation after SwapBuffers = %d, want 1", g)
+	}
+	if v := db.Front(); len(v) != 2 || v[0] != 3 {
+		t.Fatalf("Front after SwapBuffers = %v, want [3 4]", v)
+	}
+	back, _ := db.Back(context.Background())
+	if cap(*back) != 8 {
+		t.Fatalf("Back after SwapBuffers has cap %d, want 8", cap(*back))
+	}
+	*back = append(*back, 5)
+	db.Ready()
+	if v, changed := db.Next(); !changed || len(v) != 1 || v[0] != 5 {
+		t.Fatalf("Next = %v, %t; want [5], true", v, changed)
+	}
+}
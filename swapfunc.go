@@ -0,0 +1,57 @@
+package doublebuf
+
+// WithSwapFunc makes swaps exchange the contents of the buffers with swap
+// instead of moving pointers, for a T whose contents are cheaper to exchange
+// in place than to copy, e.g. a struct wrapping large slices that swap can
+// trade between the two values.
+//
+// When Next finds a readied frame, it calls swap with the current front
+// buffer and the readied buffer, under the lock that serializes swaps. swap
+// must leave the new frame in front and may leave anything in back. The
+// front buffer then stays in place, and back, holding whatever swap left in
+// it, is retired as the front buffer would be without WithSwapFunc. The
+// exact order is:
+//
+//  1. swap(front, back).
+//  2. The generation is bumped, and SwapCount and LastSwap are updated.
+//  3. back is copied for Previous, with WithPrevious.
+//  4. back is sent to the free list, where a producer may pick it up.
+//  5. Waiters for the swap are woken.
+//
+// Since the front buffer is overwritten in place, readers of Front that run
+// concurrently with Next may observe the exchange in progress; only use
+// WithSwapFunc if Front and Next are called from the same goroutine.
+// If swap panics, the readied frame is dropped and its buffer recycled, see
+// WithPanicHandler. A nil swap moves pointers, as without the option.
+func WithSwapFunc[T any](swap func(front, back *T)) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.swapFunc = swap }
+}
+
+// exchange makes the frame in next the front and returns the front slot and
+// the retired slot. It reports false if swap panicked and the panic handler
+// recovered, in which case the frame has been recycled and the seqlock
+// write section closed. db.swapMu must be held.
+func (db *DoubleBuffer[T]) exchange(next *slot[T]) (front, old *slot[T], ok bool) {
+	if db.swapFunc == nil {
+		return next, db.front.Swap(next), true
+	}
+	front = db.front.Load()
+	defer func() {
+		if !ok { // swap panicked, recycle the frame, before unwinding if not recovered
+			db.prev <- next
+			db.seq.Add(1)
+			db.swapped.notify()
+		}
+	}()
+	db.invoke("SwapFunc", func() {
+		db.swapFunc(front.p, next.p)
+		ok = true
+	})
+	if !ok {
+		return nil, nil, false
+	}
+	// The frame moved into front, so its metadata moves along with it.
+	front.readyAt = next.readyAt
+	front.dirty, next.dirty = next.dirty, front.dirty
+	return front, next, true
+}
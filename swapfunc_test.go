@@ -0,0 +1,55 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithSwapFunc(t *testing.T) {
+	type frame struct{ data []int }
+	calls := 0
+	db := New(frame{data: []int{0}}, frame{data: []int{0}}, WithSwapFunc(func(front, back *frame) {
+		calls++
+		front.data, back.data = back.data, front.data
+	}), WithPrevious[frame]())
+	front := db.front.Load()
+	for i := 1; i <= 3; i++ {
+		back, _ := db.Back(context.Background())
+		back.data[0] = i
+		db.Ready()
+		if v, changed := db.Next(); !changed || v.data[0] != i {
+			t.Fatalf("Next = %v, %t; want [%d], true", v.data, changed, i)
+		}
+		if p, _ := db.Previous(); p.data[0] != i-1 {
+			t.Fatalf("Previous = %v, want [%d]", p.data, i-1)
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("swap called %d times, want 3", calls)
+	}
+	if db.front.Load() != front {
+		t.Fatal("front buffer moved despite WithSwapFunc")
+	}
+}
+
+func TestWithSwapFuncPanicRecovered(t *testing.T) {
+	var recovered []error
+	db := New(0, 0, WithPanicHandler[int](func(err error) { recovered = append(recovered, err) }),
+		WithSwapFunc(func(front, back *int) { panic("boom") }))
+	db.Publish(context.Background(), 1)
+	if v, changed := db.Next(); changed {
+		t.Fatalf("Next after a recovered swap panic = %d, true; want no change", v)
+	}
+	if len(recovered) != 1 {
+		t.Fatalf("panic handler called %d times, want 1", len(recovered))
+	}
+	if n, g := db.SwapCount(), db.Generation(); n != 0 || g != 0 {
+		t.Fatalf("SwapCount, Generation = %d, %d; want 0, 0", n, g)
+	}
+	if db.pending() {
+		t.Fatal("frame still pending after the failed swap")
+	}
+	if _, ok := db.TryBack(); !ok {
+		t.Fatal("buffer of the failed swap not recycled")
+	}
+}
@@ -0,0 +1,60 @@
+package doublebuf
+
+import "context"
+
+// Teardown shuts the DoubleBuffer down for good and hands all its backing
+// buffers to the caller, e.g. to close the files or return the memory they
+// refer to. It closes the DoubleBuffer as Close does, then waits for
+// producers to give back the buffers they hold, and returns every backing
+// buffer, including the front and any pending frame, once none is held
+// anymore.
+//
+// A producer gives back its buffer by calling Ready or ReadyBack, which are
+// no-ops on a closed DoubleBuffer apart from that. Since a single producer
+// holds the initial back buffer from the start, a producer that never
+// readies anything keeps Teardown waiting. If ctx is done before all
+// producers have given back their buffers, Teardown returns ctx.Err() and
+// no buffers; it may be called again to keep waiting.
+//
+// After Teardown the buffers belong to the caller and the DoubleBuffer must
+// no longer be used, including the values previously returned by Front. For
+// a DoubleBuffer created by NewFromSource, the buffers are not released to
+// the BufferSource, neither by Teardown nor by a later Close.
+func (db *DoubleBuffer[T]) Teardown(ctx context.Context) ([]*T, error) {
+	db.releaseOnce.Do(func() {}) // the caller takes over the buffers
+	db.Close()
+	for {
+		// Grab the wait channel before checking, so a returned buffer is not missed.
+		returned := db.returned.wait()
+		if db.inFlight.Load() == 0 {
+			db.returned.release(returned)
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-returned:
+		}
+	}
+	if db.adapt != nil {
+		db.slotsMu.Lock()
+		defer db.slotsMu.Unlock()
+	}
+	bufs := make([]*T, len(db.slots))
+	for i, s := range db.slots {
+		bufs[i] = s.p
+	}
+	return bufs, nil
+}
+
+// giveUpBack drops the producer's back buffer once the DoubleBuffer is
+// closed, so that Teardown can take it over.
+func (db *DoubleBuffer[T]) giveUpBack() {
+	if db.back == nil {
+		return
+	}
+	db.back = nil
+	db.counted = false
+	db.inFlight.Add(-1)
+	db.returned.notify()
+}
@@ -0,0 +1,36 @@
+package doublebuf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTeardown(t *testing.T) {
+	db := New(1, 2, WithBuffers(3))
+	back, _ := db.Back(context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := db.Teardown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Teardown with a held buffer = %v, want DeadlineExceeded", err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		db.Ready() // gives the buffer back
+	}()
+	bufs, err := db.Teardown(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bufs) != 3 {
+		t.Fatalf("Teardown returned %d buffers, want 3", len(bufs))
+	}
+	found := false
+	for _, p := range bufs {
+		found = found || p == back
+	}
+	if !found {
+		t.Fatal("Teardown did not return the buffer the producer held")
+	}
+}
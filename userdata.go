@@ -0,0 +1,35 @@
+package doublebuf
+
+// WithUserData attaches data[i] to the i-th backing buffer, to bind
+// external per-buffer state, such as an encoder preallocated for that
+// buffer, without wrapping it in T. The buffers are numbered as by
+// FrontIdentity: 0 for the buffer passed to the constructor as a, 1 for b,
+// and 2 onwards for the extra buffers of WithBuffers. Buffers without an
+// entry in data, including those allocated by WithAdaptiveDepth, have nil
+// user data.
+// User data travels with the physical buffer, not with the front or back
+// role: after a swap, FrontUserData reports the data of the buffer that
+// became the front. It is never modified by the DoubleBuffer.
+func WithUserData[T any](data []any) Option[T] {
+	return func(db *DoubleBuffer[T]) { db.userData = data }
+}
+
+// FrontUserData returns the user data of the front buffer, see
+// WithUserData. It is not read in one snapshot with the front value, so
+// the consumer, which swaps, is the one to rely on its pairing with Front.
+// FrontUserData is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) FrontUserData() any {
+	return db.loadFront().data
+}
+
+// BackUserData returns the user data of the back buffer that the producer
+// holds, the one last returned by Back or TryBack, see WithUserData, or
+// nil if it holds none, e.g. right after Ready.
+// The concurrency rules of Back apply.
+func (db *DoubleBuffer[T]) BackUserData() any {
+	db.checkSingleProducer("BackUserData")
+	if db.back == nil {
+		return nil
+	}
+	return db.back.data
+}
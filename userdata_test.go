@@ -0,0 +1,41 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWithUserData(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithUserData[int]([]any{"a", "b"}))
+	if got := db.FrontUserData(); got != "b" {
+		t.Fatalf("FrontUserData = %v, want b", got)
+	}
+	if got := db.BackUserData(); got != "a" {
+		t.Fatalf("BackUserData = %v, want a", got)
+	}
+	db.Ready()
+	if got := db.BackUserData(); got != nil {
+		t.Fatalf("BackUserData after Ready = %v, want nil", got)
+	}
+	db.Next()
+	if got := db.FrontUserData(); got != "a" {
+		t.Fatalf("FrontUserData after a swap = %v, want a", got)
+	}
+	db.Back(context.Background())
+	if got := db.BackUserData(); got != nil {
+		t.Fatalf("BackUserData of the extra buffer = %v, want nil", got)
+	}
+}
+
+func TestWithUserDataCacheAligned(t *testing.T) {
+	db := New(0, 0, WithBuffers(0), WithCacheAligned[int](), WithUserData[int]([]any{"a", "b", "c"}))
+	if front, back := db.FrontUserData(), db.BackUserData(); front != "b" || back != "a" {
+		t.Fatalf("FrontUserData, BackUserData = %v, %v; want b, a", front, back)
+	}
+	db.Ready()
+	db.Next()
+	db.Back(context.Background())
+	if front, back := db.FrontUserData(), db.BackUserData(); front != "a" || back != "c" {
+		t.Fatalf("after a swap FrontUserData, BackUserData = %v, %v; want a, c", front, back)
+	}
+}
@@ -0,0 +1,38 @@
+package doublebuf
+
+// RequestYield asks the producer to give up the back buffer it holds, even
+// in the middle of filling a frame, e.g. so that a coordinator can
+// reconfigure the buffers. It only sets a flag: the request relies on the
+// producer checking ShouldYield, typically between steps of filling a
+// frame, and answering with Yield instead of Ready. A coordinator can tell
+// that the producer has yielded from InFlight dropping to 0.
+// RequestYield is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) RequestYield() { db.yield.Store(true) }
+
+// ShouldYield reports whether RequestYield was called since the producer
+// last yielded.
+// ShouldYield is safe to call concurrently with all other methods.
+func (db *DoubleBuffer[T]) ShouldYield() bool { return db.yield.Load() }
+
+// Yield gives the back buffer that the producer holds back to the free list
+// without publishing it, discarding the frame being filled, and clears the
+// request of RequestYield. The next Back takes a buffer from the free list
+// as after Ready, running the WithOnRecycle hook on it, and may block until
+// one is free; for the two buffers of New it is the buffer just yielded.
+// Apart from clearing the request, Yield is a no-op if the producer holds
+// no back buffer, or in the degraded mode of Collapse.
+// The concurrency rules of Ready apply, and Yield panics on a
+// multi-producer DoubleBuffer.
+func (db *DoubleBuffer[T]) Yield() {
+	db.checkSingleProducer("Yield")
+	db.yield.Store(false)
+	if db.back == nil || db.degraded {
+		return
+	}
+	s := db.back
+	db.back = nil
+	db.counted = false
+	db.inFlight.Add(-1)
+	db.prev <- s
+	db.returned.notify()
+}
@@ -0,0 +1,30 @@
+package doublebuf
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestYield(t *testing.T) {
+	var recycled int
+	db := New(0, 0, WithOnRecycle(func(*int) { recycled++ }))
+	back, _ := db.Back(context.Background())
+	*back = 1
+	if db.ShouldYield() {
+		t.Fatal("ShouldYield before RequestYield")
+	}
+	db.RequestYield()
+	if !db.ShouldYield() {
+		t.Fatal("ShouldYield = false after RequestYield")
+	}
+	db.Yield()
+	if db.ShouldYield() || db.InFlight() != 0 {
+		t.Fatalf("after Yield: ShouldYield = %t, InFlight = %d; want false, 0", db.ShouldYield(), db.InFlight())
+	}
+	if _, changed := db.Next(); changed {
+		t.Fatal("yielded frame was published")
+	}
+	if _, err := db.Back(context.Background()); err != nil || recycled != 1 {
+		t.Fatalf("Back after Yield = %v, recycled %d times; want nil, 1", err, recycled)
+	}
+}